@@ -13,6 +13,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/dangy/pr-reviewer-assignment-service/internal/config"
+	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
 	"github.com/dangy/pr-reviewer-assignment-service/internal/http/handlers"
 	"github.com/dangy/pr-reviewer-assignment-service/internal/repository"
 	"github.com/dangy/pr-reviewer-assignment-service/internal/service"
@@ -37,10 +38,21 @@ func main() {
 		log.Fatalf("failed to ensure schema: %v", err)
 	}
 
+	strategies, err := service.NewStrategies(cfg.ReviewerSelection, cfg.TeamReviewerSelection)
+	if err != nil {
+		log.Fatalf("failed to configure reviewer selection: %v", err)
+	}
+
 	repo := repository.New(pool)
-	svc := service.New(repo)
+	warnUnknownStrategyTeams(ctx, repo, strategies)
+	workday := domain.Workday{StartHour: cfg.SLAWorkdayStart, EndHour: cfg.SLAWorkdayEnd, Location: cfg.SLATimezone}
+	svc := service.New(repo, strategies, workday)
 	handler := handlers.New(svc, cfg.AdminToken, cfg.UserToken)
 
+	workerCtx, stopWorker := context.WithCancel(ctx)
+	defer stopWorker()
+	go service.RunSLAWorker(workerCtx, svc, cfg.SLACheckInterval)
+
 	srv := &http.Server{
 		Addr:              ":" + cfg.HTTPPort,
 		Handler:           handler.Router(),
@@ -58,6 +70,7 @@ func main() {
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 	<-stop
 
+	stopWorker()
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -67,3 +80,18 @@ func main() {
 		log.Printf("server stopped")
 	}
 }
+
+// warnUnknownStrategyTeams logs the teams TEAM_REVIEWER_SELECTION names that
+// do not exist, e.g. after a rename: they silently get the default strategy.
+func warnUnknownStrategyTeams(ctx context.Context, repo *repository.Repository, strategies *service.Strategies) {
+	for _, team := range strategies.Teams() {
+		_, err := repo.GetTeam(ctx, team)
+		if errors.Is(err, domain.ErrTeamNotFound) {
+			log.Printf("WARNING: TEAM_REVIEWER_SELECTION names unknown team %q, its strategy is not used", team)
+			continue
+		}
+		if err != nil {
+			log.Printf("failed to check team %q from TEAM_REVIEWER_SELECTION: %v", team, err)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +15,14 @@ type Config struct {
 	DatabaseURL string
 	AdminToken  string
 	UserToken   string
+
+	ReviewerSelection     string
+	TeamReviewerSelection map[string]string
+
+	SLACheckInterval time.Duration
+	SLAWorkdayStart  int
+	SLAWorkdayEnd    int
+	SLATimezone      *time.Location
 }
 
 func Load() (*Config, error) {
@@ -22,6 +33,36 @@ func Load() (*Config, error) {
 		DatabaseURL: os.Getenv("DATABASE_URL"),
 		AdminToken:  getEnv("ADMIN_TOKEN", "admin-secret"),
 		UserToken:   getEnv("USER_TOKEN", "user-secret"),
+
+		ReviewerSelection: getEnv("REVIEWER_SELECTION", "least_loaded"),
+	}
+
+	teamSelection, err := parseTeamMap(os.Getenv("TEAM_REVIEWER_SELECTION"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TEAM_REVIEWER_SELECTION: %w", err)
+	}
+	cfg.TeamReviewerSelection = teamSelection
+
+	slaInterval, err := time.ParseDuration(getEnv("SLA_CHECK_INTERVAL", "5m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLA_CHECK_INTERVAL: %w", err)
+	}
+	cfg.SLACheckInterval = slaInterval
+
+	cfg.SLAWorkdayStart, err = strconv.Atoi(getEnv("SLA_WORKDAY_START", "9"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLA_WORKDAY_START: %w", err)
+	}
+	cfg.SLAWorkdayEnd, err = strconv.Atoi(getEnv("SLA_WORKDAY_END", "18"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLA_WORKDAY_END: %w", err)
+	}
+	if cfg.SLAWorkdayStart < 0 || cfg.SLAWorkdayEnd > 24 || cfg.SLAWorkdayStart >= cfg.SLAWorkdayEnd {
+		return nil, fmt.Errorf("invalid SLA working hours %d-%d: must lie within 0-24 and end after they start", cfg.SLAWorkdayStart, cfg.SLAWorkdayEnd)
+	}
+	cfg.SLATimezone, err = time.LoadLocation(getEnv("SLA_TIMEZONE", "UTC"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLA_TIMEZONE: %w", err)
 	}
 
 	if cfg.DatabaseURL == "" {
@@ -45,3 +86,20 @@ func getEnv(key, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+func parseTeamMap(raw string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		team, value, ok := strings.Cut(entry, ":")
+		team, value = strings.TrimSpace(team), strings.TrimSpace(value)
+		if !ok || team == "" || value == "" {
+			return nil, fmt.Errorf("entry %q must look like team:value", entry)
+		}
+		out[team] = value
+	}
+	return out, nil
+}
@@ -9,6 +9,36 @@ var (
 	ErrTeamNotFound = errors.New("team not found")
 	ErrPRNotFound   = errors.New("pull request not found")
 	ErrPRMerged     = errors.New("pull request already merged")
+	ErrPRDraft      = errors.New("pull request is a draft")
+	ErrPRClosed     = errors.New("pull request is closed")
 	ErrNotAssigned  = errors.New("user is not assigned to pull request")
 	ErrNoCandidate  = errors.New("no active candidates available")
+	ErrAtCapacity   = errors.New("all candidates are at the open review limit")
+
+	ErrReviewerIsAuthor = errors.New("author cannot review own pull request")
+	ErrUserInactive     = errors.New("user is not active")
+	ErrUserUnavailable  = errors.New("user is unavailable")
+	ErrAlreadyAssigned  = errors.New("user is already assigned to pull request")
+	ErrTeamNotAllowed   = errors.New("user's team may not review this pull request")
+	ErrEnoughReviewers  = errors.New("pull request already has enough reviewers")
+
+	ErrMemberHasOpenReviews = errors.New("member has open review assignments")
+	ErrMemberHasOpenPRs     = errors.New("member is the author of open or draft pull requests")
+	ErrUserInOtherTeam      = errors.New("user already belongs to another team")
+	ErrTeamArchived         = errors.New("team is archived")
+	ErrTeamHasPRs           = errors.New("team has pull requests")
+	ErrNotTeamMember        = errors.New("user is not a member of the team")
+	ErrTeamCycle            = errors.New("team cannot be placed under itself or its subteams")
+	ErrInvalidFallbackTeams = errors.New("invalid fallback teams")
+	ErrInvalidMembers       = errors.New("invalid team members")
+
+	ErrMergeBlocked       = errors.New("merge blocked by review policy")
+	ErrAlreadyEscalated   = errors.New("overdue review was already escalated")
+	ErrInvalidReviewState = errors.New("review state must be APPROVED, CHANGES_REQUESTED or COMMENTED")
+
+	ErrOwnershipRuleNotFound = errors.New("ownership rule not found")
+	ErrNoOwners              = errors.New("ownership rule must have at least one owner")
+
+	ErrWindowNotFound = errors.New("unavailability window not found")
+	ErrInvalidWindow  = errors.New("unavailability window must end after it starts")
 )
@@ -1,38 +1,310 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 type PullRequestStatus string
 
 const (
 	PullRequestStatusOpen   PullRequestStatus = "OPEN"
 	PullRequestStatusMerged PullRequestStatus = "MERGED"
+	PullRequestStatusClosed PullRequestStatus = "CLOSED"
+	PullRequestStatusDraft  PullRequestStatus = "DRAFT"
+)
+
+type ReviewState string
+
+const (
+	ReviewStatePending          ReviewState = "PENDING"
+	ReviewStateApproved         ReviewState = "APPROVED"
+	ReviewStateChangesRequested ReviewState = "CHANGES_REQUESTED"
+	ReviewStateCommented        ReviewState = "COMMENTED"
 )
 
+// IsVerdict reports whether a reviewer can submit the state.
+func (s ReviewState) IsVerdict() bool {
+	switch s {
+	case ReviewStateApproved, ReviewStateChangesRequested, ReviewStateCommented:
+		return true
+	}
+	return false
+}
+
+const DefaultRequiredReviewers = 2
+
 type Team struct {
-	Name    string
-	Members []User
+	Name              string
+	RequiredReviewers int
+	RequiredApprovals int
+	MaxOpenReviews    int
+	ReviewSLAHours    int
+	SLAAction         SLAAction
+	FallbackTeams     []string
+	ParentTeam        string
+	Members           []User
+	ArchivedAt        *time.Time
+
+	// Subteams is only filled when the team is loaded with its subtree.
+	Subteams []Team
+}
+
+func (t Team) Archived() bool {
+	return t.ArchivedAt != nil
 }
 
+type TeamUpdate struct {
+	RequiredReviewers *int
+	RequiredApprovals *int
+	MaxOpenReviews    *int
+	ReviewSLAHours    *int
+	SLAAction         *SLAAction
+	FallbackTeams     *[]string
+	// ParentTeam moves the team under another one, an empty name detaches it.
+	ParentTeam *string
+}
+
+// User.TeamName is the user's primary team, Teams lists every team the user
+// is a member of, the primary one included.
 type User struct {
 	ID       string
 	Username string
 	TeamName string
+	Teams    []string
 	IsActive bool
+	Tags     []string
+
+	MaxOpenReviews int
+	Unavailability []UnavailabilityWindow
+}
+
+func (u User) InTeam(teamName string) bool {
+	if teamName == "" {
+		return false
+	}
+	if u.TeamName == teamName {
+		return true
+	}
+	for _, name := range u.Teams {
+		if name == teamName {
+			return true
+		}
+	}
+	return false
+}
+
+type UnavailabilityWindow struct {
+	ID     int64
+	UserID string
+	Start  time.Time
+	End    time.Time
+	Reason string
+}
+
+func (w UnavailabilityWindow) Covers(at time.Time) bool {
+	return !at.Before(w.Start) && at.Before(w.End)
 }
 
 type PullRequest struct {
 	ID                string
 	Name              string
 	AuthorID          string
+	TeamName          string
 	Status            PullRequestStatus
 	AssignedReviewers []string
+	Reviews           map[string]Review
+	FallbackReviewers map[string]string
+	RequiredReviewers int
+	RequiredApprovals int
+	ChangedFiles      []string
+	RequiredTags      []string
+	Priority          Priority
+	SizeLines         int
 	CreatedAt         time.Time
 	MergedAt          *time.Time
+	ClosedAt          *time.Time
+
+	// Shortfall is filled by operations that assign reviewers when fewer
+	// reviewers than required could be found. It is not stored.
+	Shortfall *ReviewerShortfall
+}
+
+type ShortfallReason string
+
+const (
+	ShortfallNoCandidates ShortfallReason = "NO_CANDIDATES"
+	ShortfallCapacity     ShortfallReason = "CAPACITY"
+)
+
+type ReviewerShortfall struct {
+	Missing    int
+	Reason     ShortfallReason
+	AtCapacity []string
+}
+
+func (s ReviewerShortfall) Message() string {
+	if s.Reason == ShortfallCapacity {
+		return fmt.Sprintf("%d reviewer(s) missing: every other eligible candidate is at the open review limit", s.Missing)
+	}
+	return fmt.Sprintf("%d reviewer(s) missing: no other active members are available", s.Missing)
 }
 
 func (pr PullRequest) NeedMoreReviewers() bool {
-	return len(pr.AssignedReviewers) < 2
+	required := pr.RequiredReviewers
+	if required <= 0 {
+		required = DefaultRequiredReviewers
+	}
+	return len(pr.AssignedReviewers) < required
+}
+
+// MergeBlocker returns ErrMergeBlocked with the reason when the team policy
+// does not allow the PR to be merged yet. A policy with zero required
+// approvals is disabled.
+func (pr PullRequest) MergeBlocker() error {
+	if pr.RequiredApprovals <= 0 {
+		return nil
+	}
+
+	approvals := pr.Approvals()
+	var changesRequested []string
+	for _, reviewerID := range pr.AssignedReviewers {
+		if pr.Reviews[reviewerID].State == ReviewStateChangesRequested {
+			changesRequested = append(changesRequested, reviewerID)
+		}
+	}
+
+	var reasons []string
+	if approvals < pr.RequiredApprovals {
+		reasons = append(reasons, fmt.Sprintf("%d of %d required approvals", approvals, pr.RequiredApprovals))
+	}
+	if len(changesRequested) > 0 {
+		reasons = append(reasons, "changes requested by "+strings.Join(changesRequested, ", "))
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrMergeBlocked, strings.Join(reasons, "; "))
+}
+
+// CheckOpen returns the status error for changing reviewers of a PR that is
+// not OPEN.
+func (pr PullRequest) CheckOpen() error {
+	switch pr.Status {
+	case PullRequestStatusMerged:
+		return ErrPRMerged
+	case PullRequestStatusClosed:
+		return ErrPRClosed
+	case PullRequestStatusDraft:
+		return ErrPRDraft
+	}
+	return nil
+}
+
+// CanAssign applies the reviewer rules that do not depend on availability or
+// load: the PR is OPEN and the user is an active non-author not yet assigned.
+func (pr PullRequest) CanAssign(u User) error {
+	if err := pr.CheckOpen(); err != nil {
+		return err
+	}
+	if u.ID == pr.AuthorID {
+		return ErrReviewerIsAuthor
+	}
+	if !u.IsActive {
+		return ErrUserInactive
+	}
+	for _, id := range pr.AssignedReviewers {
+		if id == u.ID {
+			return ErrAlreadyAssigned
+		}
+	}
+	return nil
+}
+
+func (pr PullRequest) LoadUnits() int {
+	return LoadUnits(pr.SizeLines)
+}
+
+func (pr PullRequest) Urgent() bool {
+	return pr.Priority == PriorityUrgent
+}
+
+func (pr PullRequest) Approvals() int {
+	approvals := 0
+	for _, reviewerID := range pr.AssignedReviewers {
+		if pr.Reviews[reviewerID].State == ReviewStateApproved {
+			approvals++
+		}
+	}
+	return approvals
+}
+
+type MergeOptions struct {
+	Force  bool
+	Reason string
+}
+
+type ForcedMerge struct {
+	ID                int64
+	PullRequestID     string
+	ForcedAt          time.Time
+	Approvals         int
+	RequiredApprovals int
+	Bypassed          string
+	Reason            string
+}
+
+type ReviewDecline struct {
+	ID            int64
+	PullRequestID string
+	UserID        string
+	ReplacedBy    string
+	Reason        string
+	DeclinedAt    time.Time
+}
+
+type Review struct {
+	State       ReviewState
+	AssignedAt  time.Time
+	SubmittedAt *time.Time
+}
+
+type PullRequestUpdate struct {
+	Name     *string
+	AuthorID *string
+}
+
+type PullRequestOptions struct {
+	// TeamName is the team the PR is created for, the author's primary team
+	// when empty.
+	TeamName     string
+	ChangedFiles []string
+	RequiredTags []string
+	Draft        bool
+	Priority     Priority
+	SizeLines    int
+}
+
+// NormalizeTags lowercases and trims tags, drops empty ones and duplicates
+// and returns them sorted.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if _, dup := seen[tag]; dup {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
 }
 
 type PullRequestShort struct {
@@ -41,3 +313,27 @@ type PullRequestShort struct {
 	AuthorID string
 	Status   PullRequestStatus
 }
+
+type ReviewerBackfill struct {
+	PullRequest    PullRequest
+	AddedReviewers []string
+}
+
+// TeamMove is one change of a user's team. An empty FromTeam means the user
+// had no team, an empty ToTeam that they were removed from it.
+type TeamMove struct {
+	ID         int64
+	UserID     string
+	FromTeam   string
+	ToTeam     string
+	Reassigned int
+	MovedAt    time.Time
+}
+
+type ReviewerReassignment struct {
+	PullRequestID string
+	OldReviewerID string
+	NewReviewerID string
+	FallbackTeam  string
+	Reason        string
+}
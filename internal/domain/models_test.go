@@ -1,10 +1,17 @@
 package domain
 
-import "testing"
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestPullRequest_NeedMoreReviewers(t *testing.T) {
 	tests := []struct {
 		name           string
+		required       int
 		reviewersCount int
 		want           bool
 	}{
@@ -23,11 +30,30 @@ func TestPullRequest_NeedMoreReviewers(t *testing.T) {
 			reviewersCount: 2,
 			want:           false,
 		},
+		{
+			name:           "one reviewer, team requires one",
+			required:       1,
+			reviewersCount: 1,
+			want:           false,
+		},
+		{
+			name:           "two reviewers, team requires three",
+			required:       3,
+			reviewersCount: 2,
+			want:           true,
+		},
+		{
+			name:           "three reviewers, team requires three",
+			required:       3,
+			reviewersCount: 3,
+			want:           false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			pr := PullRequest{
+				RequiredReviewers: tt.required,
 				AssignedReviewers: make([]string, tt.reviewersCount),
 			}
 			if got := pr.NeedMoreReviewers(); got != tt.want {
@@ -36,3 +62,149 @@ func TestPullRequest_NeedMoreReviewers(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{" Go", "sql", "", "go", "Frontend "})
+	want := []string{"frontend", "go", "sql"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeTags() = %v, want %v", got, want)
+	}
+	if got := NormalizeTags(nil); len(got) != 0 {
+		t.Errorf("NormalizeTags(nil) = %v, want empty", got)
+	}
+}
+
+func TestUnavailabilityWindow_Covers(t *testing.T) {
+	start := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	window := UnavailabilityWindow{Start: start, End: start.Add(48 * time.Hour)}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{name: "before start", at: start.Add(-time.Second), want: false},
+		{name: "at start", at: start, want: true},
+		{name: "inside", at: start.Add(24 * time.Hour), want: true},
+		{name: "at end", at: start.Add(48 * time.Hour), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := window.Covers(tt.at); got != tt.want {
+				t.Errorf("Covers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewerShortfall_Message(t *testing.T) {
+	capacity := ReviewerShortfall{Missing: 2, Reason: ShortfallCapacity}
+	if msg := capacity.Message(); !strings.Contains(msg, "2 reviewer(s)") || !strings.Contains(msg, "limit") {
+		t.Errorf("Message() = %q, want capacity explanation", msg)
+	}
+
+	empty := ReviewerShortfall{Missing: 1, Reason: ShortfallNoCandidates}
+	if msg := empty.Message(); strings.Contains(msg, "limit") {
+		t.Errorf("Message() = %q, must not blame capacity", msg)
+	}
+}
+
+func TestReviewState_IsVerdict(t *testing.T) {
+	tests := []struct {
+		state ReviewState
+		want  bool
+	}{
+		{state: ReviewStatePending, want: false},
+		{state: ReviewStateApproved, want: true},
+		{state: ReviewStateChangesRequested, want: true},
+		{state: ReviewStateCommented, want: true},
+		{state: "REJECTED", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.state), func(t *testing.T) {
+			if got := tt.state.IsVerdict(); got != tt.want {
+				t.Errorf("IsVerdict() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPullRequest_MergeBlocker(t *testing.T) {
+	tests := []struct {
+		name     string
+		required int
+		states   map[string]ReviewState
+		blocked  bool
+	}{
+		{name: "policy disabled", required: 0, states: map[string]ReviewState{"u2": ReviewStatePending}, blocked: false},
+		{name: "no reviewers", required: 1, states: map[string]ReviewState{}, blocked: true},
+		{name: "not enough approvals", required: 2, states: map[string]ReviewState{"u2": ReviewStateApproved, "u3": ReviewStateCommented}, blocked: true},
+		{name: "enough approvals", required: 1, states: map[string]ReviewState{"u2": ReviewStateApproved, "u3": ReviewStatePending}, blocked: false},
+		{name: "changes requested", required: 1, states: map[string]ReviewState{"u2": ReviewStateApproved, "u3": ReviewStateChangesRequested}, blocked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := PullRequest{RequiredApprovals: tt.required, Reviews: map[string]Review{}}
+			for id, state := range tt.states {
+				pr.AssignedReviewers = append(pr.AssignedReviewers, id)
+				pr.Reviews[id] = Review{State: state}
+			}
+			err := pr.MergeBlocker()
+			if got := errors.Is(err, ErrMergeBlocked); got != tt.blocked {
+				t.Errorf("MergeBlocker() = %v, want blocked %v", err, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestPullRequest_CanAssign(t *testing.T) {
+	open := PullRequest{AuthorID: "u1", Status: PullRequestStatusOpen, AssignedReviewers: []string{"u2"}}
+
+	tests := []struct {
+		name string
+		pr   PullRequest
+		user User
+		want error
+	}{
+		{name: "eligible", pr: open, user: User{ID: "u3", IsActive: true}},
+		{name: "author", pr: open, user: User{ID: "u1", IsActive: true}, want: ErrReviewerIsAuthor},
+		{name: "inactive", pr: open, user: User{ID: "u3"}, want: ErrUserInactive},
+		{name: "already assigned", pr: open, user: User{ID: "u2", IsActive: true}, want: ErrAlreadyAssigned},
+		{name: "merged", pr: PullRequest{AuthorID: "u1", Status: PullRequestStatusMerged}, user: User{ID: "u3", IsActive: true}, want: ErrPRMerged},
+		{name: "closed", pr: PullRequest{AuthorID: "u1", Status: PullRequestStatusClosed}, user: User{ID: "u3", IsActive: true}, want: ErrPRClosed},
+		{name: "draft", pr: PullRequest{AuthorID: "u1", Status: PullRequestStatusDraft}, user: User{ID: "u3", IsActive: true}, want: ErrPRDraft},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.pr.CanAssign(tt.user); !errors.Is(err, tt.want) {
+				t.Errorf("CanAssign() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUser_InTeam(t *testing.T) {
+	user := User{ID: "u1", TeamName: "backend", Teams: []string{"backend", "payments"}}
+
+	tests := []struct {
+		team string
+		want bool
+	}{
+		{team: "backend", want: true},
+		{team: "payments", want: true},
+		{team: "frontend", want: false},
+		{team: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.team, func(t *testing.T) {
+			if got := user.InTeam(tt.team); got != tt.want {
+				t.Errorf("InTeam(%q) = %v, want %v", tt.team, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type OwnershipRule struct {
+	ID       int64
+	Pattern  string
+	Users    []string
+	Teams    []string
+	Position int
+}
+
+type OwnershipRuleUpdate struct {
+	Pattern *string
+	Users   *[]string
+	Teams   *[]string
+}
+
+// MatchingRule follows CODEOWNERS precedence: the last rule whose pattern
+// matches the path wins.
+func MatchingRule(rules []OwnershipRule, path string) (OwnershipRule, bool) {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(rules) - 1; i >= 0; i-- {
+		if MatchOwnershipPattern(rules[i].Pattern, path) {
+			return rules[i], true
+		}
+	}
+	return OwnershipRule{}, false
+}
+
+func MatchOwnershipPattern(pattern, path string) bool {
+	re, err := ownershipPatternRegexp(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(strings.TrimPrefix(path, "/"))
+}
+
+func ValidateOwnershipPattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	_, err := ownershipPatternRegexp(pattern)
+	return err
+}
+
+func ownershipPatternRegexp(pattern string) (*regexp.Regexp, error) {
+	p := strings.TrimSpace(pattern)
+	anchored := strings.HasPrefix(p, "/")
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("pattern %q matches nothing", pattern)
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored && !strings.Contains(p, "/") {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "/**"):
+			b.WriteString("(?:/.*)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(p[i])))
+		}
+	}
+	b.WriteString("(?:/.*)?$")
+
+	return regexp.Compile(b.String())
+}
+
+// ParseCodeOwners reads a CODEOWNERS file. Owners written as @org/team or
+// @team/name are treated as teams (the last path segment is the team name),
+// any other @handle is a user ID.
+func ParseCodeOwners(content string) ([]OwnershipRule, error) {
+	var rules []OwnershipRule
+	for lineNo, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		rule := OwnershipRule{Pattern: fields[0], Position: len(rules)}
+		if err := ValidateOwnershipPattern(rule.Pattern); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo+1, err)
+		}
+		for _, owner := range fields[1:] {
+			if !strings.HasPrefix(owner, "@") || len(owner) == 1 {
+				return nil, fmt.Errorf("line %d: owner %q must start with @", lineNo+1, owner)
+			}
+			owner = owner[1:]
+			if idx := strings.LastIndex(owner, "/"); idx >= 0 {
+				rule.Teams = append(rule.Teams, owner[idx+1:])
+			} else {
+				rule.Users = append(rule.Users, owner)
+			}
+		}
+		if len(rule.Users) == 0 && len(rule.Teams) == 0 {
+			return nil, fmt.Errorf("line %d: pattern %q has no owners", lineNo+1, rule.Pattern)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchOwnershipPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "*", path: "README.md", want: true},
+		{pattern: "*.go", path: "main.go", want: true},
+		{pattern: "*.go", path: "internal/domain/models.go", want: true},
+		{pattern: "*.go", path: "web/app.ts", want: false},
+		{pattern: "/docs/", path: "docs/api/readme.md", want: true},
+		{pattern: "/docs/", path: "internal/docs/readme.md", want: false},
+		{pattern: "docs/", path: "internal/docs/readme.md", want: true},
+		{pattern: "internal/repository/", path: "internal/repository/postgres.go", want: true},
+		{pattern: "internal/repository/", path: "x/internal/repository/postgres.go", want: false},
+		{pattern: "internal/*.go", path: "internal/main.go", want: true},
+		{pattern: "internal/*.go", path: "internal/domain/models.go", want: false},
+		{pattern: "**/migrations", path: "db/pg/migrations/001.sql", want: true},
+		{pattern: "web/**", path: "web/src/app.ts", want: true},
+		{pattern: "web/**/*.css", path: "web/a/b/site.css", want: true},
+		{pattern: "file?.txt", path: "file1.txt", want: true},
+		{pattern: "file?.txt", path: "file10.txt", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			if got := MatchOwnershipPattern(tt.pattern, tt.path); got != tt.want {
+				t.Errorf("MatchOwnershipPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchingRule(t *testing.T) {
+	rules := []OwnershipRule{
+		{ID: 1, Pattern: "*", Teams: []string{"platform"}},
+		{ID: 2, Pattern: "*.sql", Users: []string{"u2"}},
+		{ID: 3, Pattern: "/web/", Teams: []string{"frontend"}},
+	}
+
+	tests := []struct {
+		path   string
+		wantID int64
+	}{
+		{path: "cmd/server/main.go", wantID: 1},
+		{path: "schema/001.sql", wantID: 2},
+		{path: "web/schema.sql", wantID: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rule, ok := MatchingRule(rules, tt.path)
+			if !ok || rule.ID != tt.wantID {
+				t.Errorf("MatchingRule(%q) = %d, %v, want %d", tt.path, rule.ID, ok, tt.wantID)
+			}
+		})
+	}
+
+	if _, ok := MatchingRule(rules[1:2], "main.go"); ok {
+		t.Error("MatchingRule() matched a path no rule covers")
+	}
+}
+
+func TestParseCodeOwners(t *testing.T) {
+	content := `
+# default owners
+*            @acme/platform
+
+/internal/repository/  @u2 @u3   # database layer
+*.ts @acme/frontend @u7
+`
+	rules, err := ParseCodeOwners(content)
+	if err != nil {
+		t.Fatalf("ParseCodeOwners() error = %v", err)
+	}
+
+	want := []OwnershipRule{
+		{Pattern: "*", Teams: []string{"platform"}, Position: 0},
+		{Pattern: "/internal/repository/", Users: []string{"u2", "u3"}, Position: 1},
+		{Pattern: "*.ts", Users: []string{"u7"}, Teams: []string{"frontend"}, Position: 2},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("ParseCodeOwners() = %+v, want %+v", rules, want)
+	}
+
+	invalid := []string{
+		"*.go",
+		"*.go user@example.com",
+		"/ @u1",
+	}
+	for _, content := range invalid {
+		if _, err := ParseCodeOwners(content); err == nil {
+			t.Errorf("ParseCodeOwners(%q) expected error", content)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Priority string
+
+const (
+	PriorityLow    Priority = "LOW"
+	PriorityNormal Priority = "NORMAL"
+	PriorityUrgent Priority = "URGENT"
+)
+
+// ParsePriority accepts the priority in any case, an empty value is NORMAL.
+func ParsePriority(s string) (Priority, error) {
+	p := Priority(strings.ToUpper(strings.TrimSpace(s)))
+	switch p {
+	case "":
+		return PriorityNormal, nil
+	case PriorityLow, PriorityNormal, PriorityUrgent:
+		return p, nil
+	}
+	return "", fmt.Errorf("priority must be one of low, normal, urgent")
+}
+
+// LinesPerLoadUnit is the PR size, in changed lines, that counts as one open
+// review for capacity and balancing.
+const LinesPerLoadUnit = 500
+
+// LoadUnits is the review load a PR of the given size puts on each of its
+// reviewers: one unit per started LinesPerLoadUnit lines and at least one, so
+// PRs without a size estimate count as a single review.
+func LoadUnits(sizeLines int) int {
+	if sizeLines <= LinesPerLoadUnit {
+		return 1
+	}
+	return (sizeLines + LinesPerLoadUnit - 1) / LinesPerLoadUnit
+}
+
+// ExceedsCapacity reports whether taking a review of the given units would put
+// a reviewer with the given load over the limit. A reviewer without open
+// reviews can always take one, otherwise a PR larger than the limit could
+// never be reviewed.
+func ExceedsCapacity(load, units, limit int) bool {
+	if load >= limit {
+		return true
+	}
+	return load > 0 && load+units > limit
+}
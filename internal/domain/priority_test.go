@@ -0,0 +1,71 @@
+package domain
+
+import "testing"
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Priority
+		wantErr bool
+	}{
+		{in: "", want: PriorityNormal},
+		{in: "low", want: PriorityLow},
+		{in: " Urgent ", want: PriorityUrgent},
+		{in: "NORMAL", want: PriorityNormal},
+		{in: "critical", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParsePriority(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePriority(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePriority(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadUnits(t *testing.T) {
+	tests := []struct {
+		lines int
+		want  int
+	}{
+		{lines: 0, want: 1},
+		{lines: 120, want: 1},
+		{lines: LinesPerLoadUnit, want: 1},
+		{lines: LinesPerLoadUnit + 1, want: 2},
+		{lines: 3 * LinesPerLoadUnit, want: 3},
+		{lines: 3*LinesPerLoadUnit + 10, want: 4},
+	}
+
+	for _, tt := range tests {
+		if got := LoadUnits(tt.lines); got != tt.want {
+			t.Errorf("LoadUnits(%d) = %d, want %d", tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestExceedsCapacity(t *testing.T) {
+	tests := []struct {
+		name               string
+		load, units, limit int
+		want               bool
+	}{
+		{name: "room for a small PR", load: 2, units: 1, limit: 3, want: false},
+		{name: "at the limit", load: 3, units: 1, limit: 3, want: true},
+		{name: "large PR does not fit", load: 2, units: 2, limit: 3, want: true},
+		{name: "large PR fits exactly", load: 1, units: 2, limit: 3, want: false},
+		{name: "idle reviewer takes any size", load: 0, units: 5, limit: 3, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExceedsCapacity(tt.load, tt.units, tt.limit); got != tt.want {
+				t.Errorf("ExceedsCapacity(%d, %d, %d) = %v, want %v", tt.load, tt.units, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
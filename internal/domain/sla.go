@@ -0,0 +1,85 @@
+package domain
+
+import "time"
+
+type SLAAction string
+
+const (
+	SLAActionReassign    SLAAction = "REASSIGN"
+	SLAActionAddReviewer SLAAction = "ADD_REVIEWER"
+)
+
+func (a SLAAction) Valid() bool {
+	return a == SLAActionReassign || a == SLAActionAddReviewer
+}
+
+// PendingReview is an assignment without a verdict on an OPEN PR whose
+// author's team has a review SLA.
+type PendingReview struct {
+	PullRequestID   string
+	PullRequestName string
+	ReviewerID      string
+	TeamName        string
+	SLAHours        int
+	Action          SLAAction
+	AssignedAt      time.Time
+	EscalatedAt     *time.Time
+	Workday         Workday
+}
+
+func (p PendingReview) DueAt() time.Time {
+	return p.Workday.AddHours(p.AssignedAt, p.SLAHours)
+}
+
+func (p PendingReview) Overdue(at time.Time) bool {
+	return p.SLAHours > 0 && at.After(p.DueAt())
+}
+
+type SLAEscalation struct {
+	PullRequestID string
+	ReviewerID    string
+	Action        SLAAction
+	NewReviewerID string
+	Reason        string
+}
+
+// Workday is the part of Monday to Friday, in Location, that review SLAs
+// count. A Workday without a Location is DefaultWorkday.
+type Workday struct {
+	StartHour int
+	EndHour   int
+	Location  *time.Location
+}
+
+// DefaultWorkday counts 09:00-18:00 UTC, nine working hours a day.
+var DefaultWorkday = Workday{StartHour: 9, EndHour: 18, Location: time.UTC}
+
+// AddHours moves start forward by the given number of working hours. Time
+// outside the working hours and weekends does not consume the SLA, so with
+// DefaultWorkday 24 hours started on Monday morning end on Wednesday
+// afternoon.
+func (w Workday) AddHours(start time.Time, hours int) time.Time {
+	if w.Location == nil {
+		w = DefaultWorkday
+	}
+	t := start.In(w.Location)
+	remaining := time.Duration(hours) * time.Hour
+	for {
+		year, month, day := t.Date()
+		opens := time.Date(year, month, day, w.StartHour, 0, 0, 0, w.Location)
+		closes := time.Date(year, month, day, w.EndHour, 0, 0, 0, w.Location)
+		next := time.Date(year, month, day+1, w.StartHour, 0, 0, 0, w.Location)
+		if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday || !t.Before(closes) {
+			t = next
+			continue
+		}
+		if t.Before(opens) {
+			t = opens
+		}
+		if left := closes.Sub(t); remaining <= left {
+			return t.Add(remaining)
+		}
+		remaining -= closes.Sub(t)
+		t = next
+	}
+}
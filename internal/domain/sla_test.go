@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkday_AddHours(t *testing.T) {
+	// 2025-11-14 is a Friday.
+	friday := time.Date(2025, 11, 14, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		hours int
+		want  time.Time
+	}{
+		{name: "within a day", start: friday, hours: 4, want: time.Date(2025, 11, 14, 14, 0, 0, 0, time.UTC)},
+		{name: "ends at closing time", start: friday, hours: 8, want: time.Date(2025, 11, 14, 18, 0, 0, 0, time.UTC)},
+		{name: "crosses friday evening", start: friday, hours: 10, want: time.Date(2025, 11, 17, 11, 0, 0, 0, time.UTC)},
+		{name: "starts friday evening", start: time.Date(2025, 11, 14, 20, 0, 0, 0, time.UTC), hours: 1, want: time.Date(2025, 11, 17, 10, 0, 0, 0, time.UTC)},
+		{name: "starts on saturday", start: time.Date(2025, 11, 15, 12, 0, 0, 0, time.UTC), hours: 3, want: time.Date(2025, 11, 17, 12, 0, 0, 0, time.UTC)},
+		{name: "starts before opening", start: time.Date(2025, 11, 17, 7, 0, 0, 0, time.UTC), hours: 1, want: time.Date(2025, 11, 17, 10, 0, 0, 0, time.UTC)},
+		{name: "24 hours span three workdays", start: time.Date(2025, 11, 17, 9, 0, 0, 0, time.UTC), hours: 24, want: time.Date(2025, 11, 19, 15, 0, 0, 0, time.UTC)},
+		{name: "zero hours", start: friday, hours: 0, want: friday},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultWorkday.AddHours(tt.start, tt.hours); !got.Equal(tt.want) {
+				t.Errorf("AddHours(%s, %d) = %s, want %s", tt.start, tt.hours, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkday_AddHoursCustom(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	workday := Workday{StartHour: 10, EndHour: 19, Location: moscow}
+
+	tests := []struct {
+		name  string
+		start time.Time
+		hours int
+		want  time.Time
+	}{
+		// Friday 15:00 UTC is 18:00 in Moscow: one working hour is left.
+		{name: "crosses friday evening in local time", start: time.Date(2025, 11, 14, 15, 0, 0, 0, time.UTC), hours: 2, want: time.Date(2025, 11, 17, 11, 0, 0, 0, moscow)},
+		// Monday 05:00 UTC is 08:00 in Moscow, before opening.
+		{name: "starts before local opening", start: time.Date(2025, 11, 17, 5, 0, 0, 0, time.UTC), hours: 1, want: time.Date(2025, 11, 17, 11, 0, 0, 0, moscow)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workday.AddHours(tt.start, tt.hours); !got.Equal(tt.want) {
+				t.Errorf("AddHours(%s, %d) = %s, want %s", tt.start, tt.hours, got, tt.want)
+			}
+		})
+	}
+
+	if got, want := (Workday{}).AddHours(time.Date(2025, 11, 17, 9, 0, 0, 0, time.UTC), 1), time.Date(2025, 11, 17, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("zero Workday AddHours() = %s, want %s", got, want)
+	}
+}
+
+func TestPendingReview_Overdue(t *testing.T) {
+	assigned := time.Date(2025, 11, 17, 9, 0, 0, 0, time.UTC)
+	review := PendingReview{AssignedAt: assigned, SLAHours: 24}
+
+	// 24 working hours from Monday 09:00 end on Wednesday at 15:00.
+	if review.Overdue(time.Date(2025, 11, 19, 14, 0, 0, 0, time.UTC)) {
+		t.Error("Overdue() before the deadline = true")
+	}
+	if !review.Overdue(time.Date(2025, 11, 19, 16, 0, 0, 0, time.UTC)) {
+		t.Error("Overdue() after the deadline = false")
+	}
+
+	review.SLAHours = 0
+	if review.Overdue(assigned.Add(1000 * time.Hour)) {
+		t.Error("Overdue() without SLA = true")
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
+)
+
+func (h *Handler) listAvailability(w http.ResponseWriter, r *http.Request) {
+	userID := strings.TrimSpace(r.URL.Query().Get("user_id"))
+	if userID == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id is required")
+		return
+	}
+
+	windows, err := h.svc.ListUnavailability(r.Context(), userID)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"user_id":        userID,
+		"unavailability": mapWindows(windows),
+	})
+}
+
+func (h *Handler) addAvailability(w http.ResponseWriter, r *http.Request) {
+	var req addAvailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	if !h.checkActor(w, r, req.UserID) {
+		return
+	}
+
+	window, err := h.svc.AddUnavailability(r.Context(), domain.UnavailabilityWindow{
+		UserID: req.UserID,
+		Start:  req.Start,
+		End:    req.End,
+		Reason: strings.TrimSpace(req.Reason),
+	})
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSONWithStatus(w, http.StatusCreated, map[string]any{
+		"window": mapWindow(window),
+	})
+}
+
+func (h *Handler) deleteAvailability(w http.ResponseWriter, r *http.Request) {
+	var req deleteAvailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	if !h.checkActor(w, r, req.UserID) {
+		return
+	}
+
+	if err := h.svc.DeleteUnavailability(r.Context(), req.UserID, req.WindowID); err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"window_id": req.WindowID,
+		"deleted":   true,
+	})
+}
+
+func mapWindows(windows []domain.UnavailabilityWindow) []map[string]any {
+	out := make([]map[string]any, 0, len(windows))
+	for _, window := range windows {
+		out = append(out, mapWindow(window))
+	}
+	return out
+}
+
+func mapWindow(window domain.UnavailabilityWindow) map[string]any {
+	return map[string]any{
+		"window_id": window.ID,
+		"start":     window.Start.UTC(),
+		"end":       window.End.UTC(),
+		"reason":    window.Reason,
+	}
+}
+
+type addAvailabilityRequest struct {
+	UserID string    `json:"user_id"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reason string    `json:"reason"`
+}
+
+type deleteAvailabilityRequest struct {
+	UserID   string `json:"user_id"`
+	WindowID int64  `json:"window_id"`
+}
+
+func (r *addAvailabilityRequest) validate() error {
+	if strings.TrimSpace(r.UserID) == "" {
+		return errors.New("user_id is required")
+	}
+	if r.Start.IsZero() {
+		return errors.New("start is required")
+	}
+	if r.End.IsZero() {
+		return errors.New("end is required")
+	}
+	if !r.End.After(r.Start) {
+		return errors.New("end must be after start")
+	}
+	return nil
+}
+
+func (r *deleteAvailabilityRequest) validate() error {
+	if strings.TrimSpace(r.UserID) == "" {
+		return errors.New("user_id is required")
+	}
+	if r.WindowID <= 0 {
+		return errors.New("window_id is required")
+	}
+	return nil
+}
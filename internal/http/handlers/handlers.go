@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
@@ -44,13 +45,47 @@ func (h *Handler) Router() http.Handler {
 
 	r.Post("/team/add", h.requireAdmin(h.createTeam))
 	r.Get("/team/get", h.requireUserOrAdmin(h.getTeam))
+	r.Post("/team/update", h.requireAdmin(h.updateTeam))
+	r.Post("/team/deactivateMembers", h.requireAdmin(h.deactivateTeamMembers))
+	r.Post("/team/addMembers", h.requireAdmin(h.addTeamMembers))
+	r.Post("/team/removeMembers", h.requireAdmin(h.removeTeamMembers))
+	r.Post("/team/rename", h.requireAdmin(h.renameTeam))
+	r.Post("/team/archive", h.requireAdmin(h.archiveTeam))
+	r.Post("/team/unarchive", h.requireAdmin(h.unarchiveTeam))
+	r.Post("/team/delete", h.requireAdmin(h.deleteTeam))
 
 	r.Post("/users/setIsActive", h.requireAdmin(h.setUserActive))
+	r.Post("/users/setTags", h.requireAdmin(h.setUserTags))
+	r.Post("/users/setCapacity", h.requireAdmin(h.setUserCapacity))
+	r.Post("/users/move", h.requireAdmin(h.moveUser))
+	r.Post("/users/joinTeam", h.requireAdmin(h.joinTeam))
+	r.Get("/users/teamHistory", h.requireUserOrAdmin(h.listTeamMoves))
 	r.Get("/users/getReview", h.requireUserOrAdmin(h.getUserReviewAssignments))
+	r.Get("/users/availability", h.requireUserOrAdmin(h.listAvailability))
+	r.Post("/users/availability/add", h.requireUserOrAdmin(h.addAvailability))
+	r.Post("/users/availability/delete", h.requireUserOrAdmin(h.deleteAvailability))
 
 	r.Post("/pullRequest/create", h.requireAdmin(h.createPullRequest))
 	r.Post("/pullRequest/merge", h.requireAdmin(h.mergePullRequest))
+	r.Post("/pullRequest/update", h.requireAdmin(h.updatePullRequest))
+	r.Post("/pullRequest/ready", h.requireAdmin(h.markPullRequestReady))
+	r.Post("/pullRequest/close", h.requireAdmin(h.closePullRequest))
+	r.Post("/pullRequest/reopen", h.requireAdmin(h.reopenPullRequest))
 	r.Post("/pullRequest/reassign", h.requireAdmin(h.reassignReviewer))
+	r.Post("/pullRequest/addReviewer", h.requireAdmin(h.addReviewer))
+	r.Post("/pullRequest/removeReviewer", h.requireAdmin(h.removeReviewer))
+	r.Post("/pullRequest/swapReviewer", h.requireAdmin(h.swapReviewer))
+	r.Post("/pullRequest/backfill", h.requireAdmin(h.backfillReviewers))
+	r.Post("/pullRequest/review", h.requireUserOrAdmin(h.submitReview))
+	r.Post("/pullRequest/decline", h.requireUserOrAdmin(h.declineReview))
+	r.Get("/pullRequest/forcedMerges", h.requireAdmin(h.listForcedMerges))
+	r.Get("/pullRequest/overdue", h.requireUserOrAdmin(h.listOverdueReviews))
+
+	r.Get("/codeOwners/list", h.requireUserOrAdmin(h.listOwnershipRules))
+	r.Post("/codeOwners/add", h.requireAdmin(h.createOwnershipRule))
+	r.Post("/codeOwners/update", h.requireAdmin(h.updateOwnershipRule))
+	r.Post("/codeOwners/delete", h.requireAdmin(h.deleteOwnershipRule))
+	r.Post("/codeOwners/upload", h.requireAdmin(h.uploadCodeOwners))
 
 	r.Get("/stats/reviewers", h.requireUserOrAdmin(h.getReviewerStats))
 	r.Get("/stats/pullRequests", h.requireUserOrAdmin(h.getPRStats))
@@ -73,17 +108,25 @@ func (h *Handler) createTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	team := domain.Team{Name: req.TeamName}
-	for _, m := range req.Members {
-		team.Members = append(team.Members, domain.User{
-			ID:       m.UserID,
-			Username: m.Username,
-			TeamName: req.TeamName,
-			IsActive: m.IsActive,
-		})
+	team := domain.Team{Name: req.TeamName, FallbackTeams: req.FallbackTeams, ParentTeam: strings.TrimSpace(req.ParentTeam)}
+	if req.RequiredReviewers != nil {
+		team.RequiredReviewers = *req.RequiredReviewers
+	}
+	if req.MaxOpenReviews != nil {
+		team.MaxOpenReviews = *req.MaxOpenReviews
+	}
+	if req.RequiredApprovals != nil {
+		team.RequiredApprovals = *req.RequiredApprovals
+	}
+	if req.ReviewSLAHours != nil {
+		team.ReviewSLAHours = *req.ReviewSLAHours
 	}
+	if req.SLAAction != nil {
+		team.SLAAction = domain.SLAAction(*req.SLAAction)
+	}
+	team.Members = memberUsers(req.TeamName, req.Members)
 
-	created, err := h.svc.CreateTeam(r.Context(), team)
+	created, err := h.svc.CreateTeam(r.Context(), team, req.AllowMoves)
 	if err != nil {
 		status, code, message := mapDomainError(err)
 		writeError(w, status, code, message)
@@ -101,8 +144,18 @@ func (h *Handler) getTeam(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name is required")
 		return
 	}
+	subtree, err := queryFlag(r, "subtree")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
 
-	team, err := h.svc.GetTeam(r.Context(), teamName)
+	var team domain.Team
+	if subtree {
+		team, err = h.svc.GetTeamTree(r.Context(), teamName)
+	} else {
+		team, err = h.svc.GetTeam(r.Context(), teamName)
+	}
 	if err != nil {
 		status, code, message := mapDomainError(err)
 		writeError(w, status, code, message)
@@ -112,6 +165,45 @@ func (h *Handler) getTeam(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, mapTeam(team))
 }
 
+func (h *Handler) updateTeam(w http.ResponseWriter, r *http.Request) {
+	var req updateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	update := domain.TeamUpdate{
+		RequiredReviewers: req.RequiredReviewers,
+		RequiredApprovals: req.RequiredApprovals,
+		MaxOpenReviews:    req.MaxOpenReviews,
+		ReviewSLAHours:    req.ReviewSLAHours,
+		FallbackTeams:     req.FallbackTeams,
+	}
+	if req.ParentTeam != nil {
+		parent := strings.TrimSpace(*req.ParentTeam)
+		update.ParentTeam = &parent
+	}
+	if req.SLAAction != nil {
+		action := domain.SLAAction(*req.SLAAction)
+		update.SLAAction = &action
+	}
+
+	team, err := h.svc.UpdateTeam(r.Context(), req.TeamName, update)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team": mapTeam(team),
+	})
+}
+
 func (h *Handler) setUserActive(w http.ResponseWriter, r *http.Request) {
 	var req setUserActiveRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -123,6 +215,21 @@ func (h *Handler) setUserActive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.ReassignReviews {
+		user, moves, err := h.svc.DeactivateUser(r.Context(), req.UserID)
+		if err != nil {
+			status, code, message := mapDomainError(err)
+			writeError(w, status, code, message)
+			return
+		}
+
+		respondJSON(w, http.StatusOK, map[string]any{
+			"user":          mapUser(user),
+			"reassignments": mapReassignments(moves),
+		})
+		return
+	}
+
 	user, err := h.svc.SetUserActivity(r.Context(), req.UserID, req.IsActive)
 	if err != nil {
 		status, code, message := mapDomainError(err)
@@ -135,6 +242,81 @@ func (h *Handler) setUserActive(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *Handler) setUserTags(w http.ResponseWriter, r *http.Request) {
+	var req setUserTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	user, err := h.svc.SetUserTags(r.Context(), req.UserID, req.Tags)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"user": mapUser(user),
+	})
+}
+
+func (h *Handler) setUserCapacity(w http.ResponseWriter, r *http.Request) {
+	var req setUserCapacityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	user, err := h.svc.SetUserCapacity(r.Context(), req.UserID, *req.MaxOpenReviews)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"user": mapUser(user),
+	})
+}
+
+func (h *Handler) deactivateTeamMembers(w http.ResponseWriter, r *http.Request) {
+	var req deactivateTeamMembersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	users, moves, err := h.svc.DeactivateTeamMembers(r.Context(), req.TeamName, req.UserIDs, req.ReassignReviews)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	response := make([]map[string]any, 0, len(users))
+	for _, user := range users {
+		response = append(response, mapUser(user))
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"users":         response,
+		"reassignments": mapReassignments(moves),
+	})
+}
+
 func (h *Handler) createPullRequest(w http.ResponseWriter, r *http.Request) {
 	var req createPullRequestRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -146,16 +328,29 @@ func (h *Handler) createPullRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := h.svc.CreatePullRequest(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID)
+	priority, _ := domain.ParsePriority(req.Priority)
+	pr, err := h.svc.CreatePullRequest(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID, domain.PullRequestOptions{
+		TeamName:     strings.TrimSpace(req.TeamName),
+		ChangedFiles: req.ChangedFiles,
+		RequiredTags: req.RequiredTags,
+		Draft:        req.Draft,
+		Priority:     priority,
+		SizeLines:    req.SizeLines,
+	})
 	if err != nil {
 		status, code, message := mapDomainError(err)
 		writeError(w, status, code, message)
 		return
 	}
 
-	respondJSONWithStatus(w, http.StatusCreated, map[string]any{
+	response := map[string]any{
 		"pr": mapPullRequest(pr),
-	})
+	}
+	if pr.Shortfall != nil {
+		response["missing_reviewers"] = mapShortfall(*pr.Shortfall)
+	}
+
+	respondJSONWithStatus(w, http.StatusCreated, response)
 }
 
 func (h *Handler) mergePullRequest(w http.ResponseWriter, r *http.Request) {
@@ -169,7 +364,10 @@ func (h *Handler) mergePullRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := h.svc.MergePullRequest(r.Context(), req.PullRequestID)
+	pr, err := h.svc.MergePullRequest(r.Context(), req.PullRequestID, domain.MergeOptions{
+		Force:  req.Force,
+		Reason: strings.TrimSpace(req.Reason),
+	})
 	if err != nil {
 		status, code, message := mapDomainError(err)
 		writeError(w, status, code, message)
@@ -181,6 +379,164 @@ func (h *Handler) mergePullRequest(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *Handler) updatePullRequest(w http.ResponseWriter, r *http.Request) {
+	var req updatePullRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	pr, moves, err := h.svc.UpdatePullRequest(r.Context(), req.PullRequestID, domain.PullRequestUpdate{
+		Name:     req.PullRequestName,
+		AuthorID: req.AuthorID,
+	})
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"pr":            mapPullRequest(pr),
+		"reassignments": mapReassignments(moves),
+	})
+}
+
+func (h *Handler) markPullRequestReady(w http.ResponseWriter, r *http.Request) {
+	var req pullRequestIDRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	pr, err := h.svc.MarkPullRequestReady(r.Context(), req.PullRequestID)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	response := map[string]any{
+		"pr": mapPullRequest(pr),
+	}
+	if pr.Shortfall != nil {
+		response["missing_reviewers"] = mapShortfall(*pr.Shortfall)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) closePullRequest(w http.ResponseWriter, r *http.Request) {
+	var req pullRequestIDRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	pr, err := h.svc.ClosePullRequest(r.Context(), req.PullRequestID)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"pr": mapPullRequest(pr),
+	})
+}
+
+func (h *Handler) reopenPullRequest(w http.ResponseWriter, r *http.Request) {
+	var req pullRequestIDRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	pr, moves, err := h.svc.ReopenPullRequest(r.Context(), req.PullRequestID)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"pr":            mapPullRequest(pr),
+		"reassignments": mapReassignments(moves),
+	})
+}
+
+func (h *Handler) listForcedMerges(w http.ResponseWriter, r *http.Request) {
+	merges, err := h.svc.ListForcedMerges(r.Context())
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	response := make([]map[string]any, 0, len(merges))
+	for _, m := range merges {
+		response = append(response, map[string]any{
+			"merge_id":           m.ID,
+			"pull_request_id":    m.PullRequestID,
+			"forcedAt":           m.ForcedAt.UTC(),
+			"approvals":          m.Approvals,
+			"required_approvals": m.RequiredApprovals,
+			"bypassed":           m.Bypassed,
+			"reason":             m.Reason,
+		})
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"forced_merges": response,
+	})
+}
+
+func (h *Handler) listOverdueReviews(w http.ResponseWriter, r *http.Request) {
+	overdue, err := h.svc.ListOverdueReviews(r.Context(), time.Now())
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	response := make([]map[string]any, 0, len(overdue))
+	for _, item := range overdue {
+		entry := map[string]any{
+			"pull_request_id":   item.PullRequestID,
+			"pull_request_name": item.PullRequestName,
+			"reviewer_id":       item.ReviewerID,
+			"team_name":         item.TeamName,
+			"sla_action":        item.Action,
+			"assignedAt":        item.AssignedAt.UTC(),
+			"dueAt":             item.DueAt().UTC(),
+		}
+		if item.EscalatedAt != nil {
+			entry["escalatedAt"] = item.EscalatedAt.UTC()
+		}
+		response = append(response, entry)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"overdue": response,
+	})
+}
+
 func (h *Handler) reassignReviewer(w http.ResponseWriter, r *http.Request) {
 	var req reassignReviewerRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -192,7 +548,7 @@ func (h *Handler) reassignReviewer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, replacement, err := h.svc.ReassignReviewer(r.Context(), req.PullRequestID, req.OldUserID)
+	pr, replacement, err := h.svc.ReassignReviewer(r.Context(), req.PullRequestID, req.OldUserID, strings.TrimSpace(req.NewUserID))
 	if err != nil {
 		status, code, message := mapDomainError(err)
 		writeError(w, status, code, message)
@@ -205,6 +561,58 @@ func (h *Handler) reassignReviewer(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *Handler) submitReview(w http.ResponseWriter, r *http.Request) {
+	var req submitReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	if !h.checkActor(w, r, req.ReviewerID) {
+		return
+	}
+
+	pr, err := h.svc.SubmitReview(r.Context(), req.PullRequestID, req.ReviewerID, domain.ReviewState(req.State))
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"pr": mapPullRequest(pr),
+	})
+}
+
+func (h *Handler) backfillReviewers(w http.ResponseWriter, r *http.Request) {
+	results, err := h.svc.BackfillReviewers(r.Context())
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	response := make([]map[string]any, 0, len(results))
+	for _, res := range results {
+		item := map[string]any{
+			"pr":              mapPullRequest(res.PullRequest),
+			"added_reviewers": res.AddedReviewers,
+		}
+		if res.PullRequest.Shortfall != nil {
+			item["missing_reviewers"] = mapShortfall(*res.PullRequest.Shortfall)
+		}
+		response = append(response, item)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"updated": response,
+	})
+}
+
 func (h *Handler) getUserReviewAssignments(w http.ResponseWriter, r *http.Request) {
 	userID := strings.TrimSpace(r.URL.Query().Get("user_id"))
 	if userID == "" {
@@ -236,7 +644,12 @@ func (h *Handler) getUserReviewAssignments(w http.ResponseWriter, r *http.Reques
 }
 
 func (h *Handler) getReviewerStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.svc.GetReviewerStats(r.Context())
+	subtree, err := queryFlag(r, "subtree")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+	stats, err := h.svc.GetReviewerStats(r.Context(), r.URL.Query().Get("team_name"), subtree)
 	if err != nil {
 		status, code, message := mapDomainError(err)
 		writeError(w, status, code, message)
@@ -249,6 +662,8 @@ func (h *Handler) getReviewerStats(w http.ResponseWriter, r *http.Request) {
 			"user_id":           s.UserID,
 			"username":          s.Username,
 			"total_assignments": s.TotalAssignments,
+			"declines":          s.Declines,
+			"decline_rate":      s.DeclineRate(),
 		})
 	}
 
@@ -258,7 +673,12 @@ func (h *Handler) getReviewerStats(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) getPRStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.svc.GetPRStats(r.Context())
+	subtree, err := queryFlag(r, "subtree")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+	stats, err := h.svc.GetPRStats(r.Context(), r.URL.Query().Get("team_name"), subtree)
 	if err != nil {
 		status, code, message := mapDomainError(err)
 		writeError(w, status, code, message)
@@ -269,11 +689,26 @@ func (h *Handler) getPRStats(w http.ResponseWriter, r *http.Request) {
 		"total_prs":             stats.TotalPRs,
 		"open_prs":              stats.OpenPRs,
 		"merged_prs":            stats.MergedPRs,
+		"closed_prs":            stats.ClosedPRs,
+		"draft_prs":             stats.DraftPRs,
 		"prs_with_reviewers":    stats.PRsWithReviewers,
 		"prs_without_reviewers": stats.PRsWithoutReviewers,
 	})
 }
 
+// queryFlag reads an optional boolean query parameter, absent means false.
+func queryFlag(r *http.Request, name string) (bool, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(name))
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, errors.New(name + " must be true or false")
+	}
+	return value, nil
+}
+
 func (h *Handler) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if !h.authorize(r, h.adminToken) {
@@ -294,6 +729,29 @@ func (h *Handler) requireUserOrAdmin(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// actorHeader names the user a user-token caller acts as. The user token is
+// shared, so the header is the caller's declaration, not authentication.
+const actorHeader = "X-User-ID"
+
+// checkActor lets user-token calls act only for the user named in the
+// X-User-ID header; the admin token may act for anyone. It writes the error
+// response and returns false when the call is rejected.
+func (h *Handler) checkActor(w http.ResponseWriter, r *http.Request, userID string) bool {
+	if h.authorize(r, h.adminToken) {
+		return true
+	}
+	actor := strings.TrimSpace(r.Header.Get(actorHeader))
+	if actor == "" {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", actorHeader+" header is required")
+		return false
+	}
+	if actor != userID {
+		writeError(w, http.StatusForbidden, "FORBIDDEN", "cannot act on behalf of another user")
+		return false
+	}
+	return true
+}
+
 func (h *Handler) authorize(r *http.Request, tokens ...string) bool {
 	authHeader := strings.TrimSpace(r.Header.Get("Authorization"))
 	if authHeader == "" {
@@ -318,34 +776,123 @@ func mapTeam(team domain.Team) map[string]any {
 	members := make([]map[string]any, 0, len(team.Members))
 	for _, member := range team.Members {
 		members = append(members, map[string]any{
-			"user_id":   member.ID,
-			"username":  member.Username,
-			"is_active": member.IsActive,
+			"user_id":          member.ID,
+			"username":         member.Username,
+			"is_active":        member.IsActive,
+			"teams":            tagsOrEmpty(member.Teams),
+			"tags":             tagsOrEmpty(member.Tags),
+			"max_open_reviews": member.MaxOpenReviews,
+			"unavailability":   mapWindows(member.Unavailability),
 		})
 	}
 
-	return map[string]any{
-		"team_name": team.Name,
-		"members":   members,
+	fallbackTeams := team.FallbackTeams
+	if fallbackTeams == nil {
+		fallbackTeams = []string{}
+	}
+
+	response := map[string]any{
+		"team_name":          team.Name,
+		"required_reviewers": team.RequiredReviewers,
+		"required_approvals": team.RequiredApprovals,
+		"max_open_reviews":   team.MaxOpenReviews,
+		"review_sla_hours":   team.ReviewSLAHours,
+		"sla_action":         team.SLAAction,
+		"fallback_teams":     fallbackTeams,
+		"parent_team":        team.ParentTeam,
+		"members":            members,
+	}
+	if team.ArchivedAt != nil {
+		response["archived_at"] = team.ArchivedAt.UTC()
+	}
+	if team.Subteams != nil {
+		subteams := make([]map[string]any, 0, len(team.Subteams))
+		for _, sub := range team.Subteams {
+			subteams = append(subteams, mapTeam(sub))
+		}
+		response["subteams"] = subteams
 	}
+	return response
 }
 
 func mapUser(user domain.User) map[string]any {
 	return map[string]any{
-		"user_id":   user.ID,
-		"username":  user.Username,
-		"team_name": user.TeamName,
-		"is_active": user.IsActive,
+		"user_id":          user.ID,
+		"username":         user.Username,
+		"team_name":        user.TeamName,
+		"teams":            tagsOrEmpty(user.Teams),
+		"is_active":        user.IsActive,
+		"tags":             tagsOrEmpty(user.Tags),
+		"max_open_reviews": user.MaxOpenReviews,
 	}
 }
 
+func mapShortfall(shortfall domain.ReviewerShortfall) map[string]any {
+	payload := map[string]any{
+		"count":   shortfall.Missing,
+		"reason":  string(shortfall.Reason),
+		"message": shortfall.Message(),
+	}
+	if len(shortfall.AtCapacity) > 0 {
+		payload["at_capacity"] = shortfall.AtCapacity
+	}
+	return payload
+}
+
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func mapReassignments(moves []domain.ReviewerReassignment) []map[string]any {
+	out := make([]map[string]any, 0, len(moves))
+	for _, move := range moves {
+		item := map[string]any{
+			"pull_request_id": move.PullRequestID,
+			"old_user_id":     move.OldReviewerID,
+		}
+		if move.NewReviewerID != "" {
+			item["replaced_by"] = move.NewReviewerID
+			if move.FallbackTeam != "" {
+				item["fallback_team"] = move.FallbackTeam
+			}
+		} else {
+			item["reason"] = move.Reason
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
 func mapPullRequest(pr domain.PullRequest) map[string]any {
 	payload := map[string]any{
 		"pull_request_id":    pr.ID,
 		"pull_request_name":  pr.Name,
 		"author_id":          pr.AuthorID,
 		"status":             string(pr.Status),
-		"assigned_reviewers": pr.AssignedReviewers,
+		"assigned_reviewers": mapReviewers(pr),
+	}
+
+	if pr.TeamName != "" {
+		payload["team_name"] = pr.TeamName
+	}
+	if pr.Priority != "" {
+		payload["priority"] = string(pr.Priority)
+	}
+	if pr.SizeLines > 0 {
+		payload["size_lines"] = pr.SizeLines
+		payload["load_units"] = pr.LoadUnits()
+	}
+	if len(pr.FallbackReviewers) > 0 {
+		payload["fallback_reviewers"] = pr.FallbackReviewers
+	}
+	if len(pr.ChangedFiles) > 0 {
+		payload["changed_files"] = pr.ChangedFiles
+	}
+	if len(pr.RequiredTags) > 0 {
+		payload["required_tags"] = pr.RequiredTags
 	}
 
 	if !pr.CreatedAt.IsZero() {
@@ -356,10 +903,35 @@ func mapPullRequest(pr domain.PullRequest) map[string]any {
 		merged := pr.MergedAt.UTC()
 		payload["mergedAt"] = merged
 	}
+	if pr.ClosedAt != nil {
+		payload["closedAt"] = pr.ClosedAt.UTC()
+	}
 
 	return payload
 }
 
+func mapReviewers(pr domain.PullRequest) []map[string]any {
+	out := make([]map[string]any, 0, len(pr.AssignedReviewers))
+	for _, reviewerID := range pr.AssignedReviewers {
+		review, ok := pr.Reviews[reviewerID]
+		if !ok {
+			review.State = domain.ReviewStatePending
+		}
+		item := map[string]any{
+			"user_id": reviewerID,
+			"state":   string(review.State),
+		}
+		if !review.AssignedAt.IsZero() {
+			item["assignedAt"] = review.AssignedAt.UTC()
+		}
+		if review.SubmittedAt != nil {
+			item["submittedAt"] = review.SubmittedAt.UTC()
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
 func writeError(w http.ResponseWriter, status int, code string, message string) {
 	respondJSONWithStatus(w, status, errorBody{
 		Error: apiError{Code: code, Message: message},
@@ -380,34 +952,112 @@ func respondJSONWithStatus(w http.ResponseWriter, status int, payload any) {
 }
 
 type createTeamRequest struct {
-	TeamName string              `json:"team_name"`
-	Members  []teamMemberRequest `json:"members"`
+	TeamName          string              `json:"team_name"`
+	RequiredReviewers *int                `json:"required_reviewers"`
+	RequiredApprovals *int                `json:"required_approvals"`
+	MaxOpenReviews    *int                `json:"max_open_reviews"`
+	ReviewSLAHours    *int                `json:"review_sla_hours"`
+	SLAAction         *string             `json:"sla_action"`
+	FallbackTeams     []string            `json:"fallback_teams"`
+	ParentTeam        string              `json:"parent_team"`
+	Members           []teamMemberRequest `json:"members"`
+	AllowMoves        bool                `json:"allow_moves"`
+}
+
+type updateTeamRequest struct {
+	TeamName          string    `json:"team_name"`
+	RequiredReviewers *int      `json:"required_reviewers"`
+	RequiredApprovals *int      `json:"required_approvals"`
+	MaxOpenReviews    *int      `json:"max_open_reviews"`
+	ReviewSLAHours    *int      `json:"review_sla_hours"`
+	SLAAction         *string   `json:"sla_action"`
+	FallbackTeams     *[]string `json:"fallback_teams"`
+	ParentTeam        *string   `json:"parent_team"`
 }
 
 type teamMemberRequest struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID         string   `json:"user_id"`
+	Username       string   `json:"username"`
+	IsActive       bool     `json:"is_active"`
+	Tags           []string `json:"tags"`
+	MaxOpenReviews int      `json:"max_open_reviews"`
+}
+
+func memberUsers(teamName string, members []teamMemberRequest) []domain.User {
+	users := make([]domain.User, 0, len(members))
+	for _, m := range members {
+		users = append(users, domain.User{
+			ID:             m.UserID,
+			Username:       m.Username,
+			TeamName:       teamName,
+			IsActive:       m.IsActive,
+			Tags:           m.Tags,
+			MaxOpenReviews: m.MaxOpenReviews,
+		})
+	}
+	return users
 }
 
 type setUserActiveRequest struct {
-	UserID   string `json:"user_id"`
-	IsActive bool   `json:"is_active"`
+	UserID          string `json:"user_id"`
+	IsActive        bool   `json:"is_active"`
+	ReassignReviews bool   `json:"reassign_reviews"`
+}
+
+type setUserCapacityRequest struct {
+	UserID         string `json:"user_id"`
+	MaxOpenReviews *int   `json:"max_open_reviews"`
+}
+
+type setUserTagsRequest struct {
+	UserID string   `json:"user_id"`
+	Tags   []string `json:"tags"`
+}
+
+type deactivateTeamMembersRequest struct {
+	TeamName        string   `json:"team_name"`
+	UserIDs         []string `json:"user_ids"`
+	ReassignReviews bool     `json:"reassign_reviews"`
 }
 
 type createPullRequestRequest struct {
-	PullRequestID   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
-	AuthorID        string `json:"author_id"`
+	PullRequestID   string   `json:"pull_request_id"`
+	PullRequestName string   `json:"pull_request_name"`
+	AuthorID        string   `json:"author_id"`
+	TeamName        string   `json:"team_name"`
+	ChangedFiles    []string `json:"changed_files"`
+	RequiredTags    []string `json:"required_tags"`
+	Draft           bool     `json:"draft"`
+	Priority        string   `json:"priority"`
+	SizeLines       int      `json:"size_lines"`
+}
+
+type submitReviewRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	State         string `json:"state"`
 }
 
 type mergePullRequestRequest struct {
 	PullRequestID string `json:"pull_request_id"`
+	Force         bool   `json:"force"`
+	Reason        string `json:"reason"`
+}
+
+type updatePullRequestRequest struct {
+	PullRequestID   string  `json:"pull_request_id"`
+	PullRequestName *string `json:"pull_request_name"`
+	AuthorID        *string `json:"author_id"`
+}
+
+type pullRequestIDRequest struct {
+	PullRequestID string `json:"pull_request_id"`
 }
 
 type reassignReviewerRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_user_id"`
+	NewUserID     string `json:"new_user_id"`
 }
 
 func mapDomainError(err error) (int, string, string) {
@@ -418,11 +1068,49 @@ func mapDomainError(err error) (int, string, string) {
 		return http.StatusConflict, "PR_EXISTS", err.Error()
 	case errors.Is(err, domain.ErrPRMerged):
 		return http.StatusConflict, "PR_MERGED", err.Error()
+	case errors.Is(err, domain.ErrPRDraft):
+		return http.StatusConflict, "PR_DRAFT", err.Error()
+	case errors.Is(err, domain.ErrPRClosed):
+		return http.StatusConflict, "PR_CLOSED", err.Error()
 	case errors.Is(err, domain.ErrNotAssigned):
 		return http.StatusConflict, "NOT_ASSIGNED", err.Error()
+	case errors.Is(err, domain.ErrAlreadyAssigned):
+		return http.StatusConflict, "ALREADY_ASSIGNED", err.Error()
+	case errors.Is(err, domain.ErrReviewerIsAuthor):
+		return http.StatusConflict, "REVIEWER_IS_AUTHOR", err.Error()
+	case errors.Is(err, domain.ErrUserInactive):
+		return http.StatusConflict, "USER_INACTIVE", err.Error()
+	case errors.Is(err, domain.ErrUserUnavailable):
+		return http.StatusConflict, "USER_UNAVAILABLE", err.Error()
+	case errors.Is(err, domain.ErrTeamNotAllowed):
+		return http.StatusConflict, "TEAM_NOT_ALLOWED", err.Error()
+	case errors.Is(err, domain.ErrMemberHasOpenReviews):
+		return http.StatusConflict, "MEMBER_HAS_OPEN_REVIEWS", err.Error()
+	case errors.Is(err, domain.ErrMemberHasOpenPRs):
+		return http.StatusConflict, "MEMBER_HAS_OPEN_PRS", err.Error()
+	case errors.Is(err, domain.ErrUserInOtherTeam):
+		return http.StatusConflict, "USER_IN_OTHER_TEAM", err.Error()
+	case errors.Is(err, domain.ErrTeamArchived):
+		return http.StatusConflict, "TEAM_ARCHIVED", err.Error()
+	case errors.Is(err, domain.ErrTeamHasPRs):
+		return http.StatusConflict, "TEAM_HAS_PRS", err.Error()
+	case errors.Is(err, domain.ErrNotTeamMember):
+		return http.StatusConflict, "NOT_TEAM_MEMBER", err.Error()
+	case errors.Is(err, domain.ErrTeamCycle):
+		return http.StatusConflict, "TEAM_CYCLE", err.Error()
 	case errors.Is(err, domain.ErrNoCandidate):
 		return http.StatusConflict, "NO_CANDIDATE", err.Error()
-	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrTeamNotFound), errors.Is(err, domain.ErrPRNotFound):
+	case errors.Is(err, domain.ErrAtCapacity):
+		return http.StatusConflict, "AT_CAPACITY", err.Error()
+	case errors.Is(err, domain.ErrAlreadyEscalated):
+		return http.StatusConflict, "ALREADY_ESCALATED", err.Error()
+	case errors.Is(err, domain.ErrMergeBlocked):
+		return http.StatusPreconditionFailed, "MERGE_BLOCKED", err.Error()
+	case errors.Is(err, domain.ErrNoOwners), errors.Is(err, domain.ErrInvalidWindow), errors.Is(err, domain.ErrInvalidReviewState),
+		errors.Is(err, domain.ErrInvalidFallbackTeams), errors.Is(err, domain.ErrInvalidMembers):
+		return http.StatusBadRequest, "BAD_REQUEST", err.Error()
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrTeamNotFound), errors.Is(err, domain.ErrPRNotFound),
+		errors.Is(err, domain.ErrOwnershipRuleNotFound), errors.Is(err, domain.ErrWindowNotFound):
 		return http.StatusNotFound, "NOT_FOUND", err.Error()
 	default:
 		return http.StatusInternalServerError, "INTERNAL_ERROR", "internal error"
@@ -433,13 +1121,69 @@ func (r *createTeamRequest) validate() error {
 	if strings.TrimSpace(r.TeamName) == "" {
 		return errors.New("team_name is required")
 	}
-	for idx, member := range r.Members {
+	if r.RequiredReviewers != nil && *r.RequiredReviewers <= 0 {
+		return errors.New("required_reviewers must be positive")
+	}
+	if r.MaxOpenReviews != nil && *r.MaxOpenReviews < 0 {
+		return errors.New("max_open_reviews must not be negative")
+	}
+	if r.RequiredApprovals != nil && *r.RequiredApprovals < 0 {
+		return errors.New("required_approvals must not be negative")
+	}
+	if err := validateSLA(r.ReviewSLAHours, r.SLAAction); err != nil {
+		return err
+	}
+	return validateMembers(r.Members)
+}
+
+func validateMembers(members []teamMemberRequest) error {
+	seen := make(map[string]struct{}, len(members))
+	for idx, member := range members {
 		if strings.TrimSpace(member.UserID) == "" {
 			return errors.New("members[" + strconv.Itoa(idx) + "].user_id is required")
 		}
+		if _, dup := seen[member.UserID]; dup {
+			return errors.New("members[" + strconv.Itoa(idx) + "].user_id " + strconv.Quote(member.UserID) + " is listed twice")
+		}
+		seen[member.UserID] = struct{}{}
 		if strings.TrimSpace(member.Username) == "" {
 			return errors.New("members[" + strconv.Itoa(idx) + "].username is required")
 		}
+		if err := validateTags("members["+strconv.Itoa(idx)+"].tags", member.Tags); err != nil {
+			return err
+		}
+		if member.MaxOpenReviews < 0 {
+			return errors.New("members[" + strconv.Itoa(idx) + "].max_open_reviews must not be negative")
+		}
+	}
+	return nil
+}
+
+func (r *updateTeamRequest) validate() error {
+	if strings.TrimSpace(r.TeamName) == "" {
+		return errors.New("team_name is required")
+	}
+	if r.RequiredReviewers != nil && *r.RequiredReviewers <= 0 {
+		return errors.New("required_reviewers must be positive")
+	}
+	if r.MaxOpenReviews != nil && *r.MaxOpenReviews < 0 {
+		return errors.New("max_open_reviews must not be negative")
+	}
+	if r.RequiredApprovals != nil && *r.RequiredApprovals < 0 {
+		return errors.New("required_approvals must not be negative")
+	}
+	if err := validateSLA(r.ReviewSLAHours, r.SLAAction); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateSLA(hours *int, action *string) error {
+	if hours != nil && *hours < 0 {
+		return errors.New("review_sla_hours must not be negative")
+	}
+	if action != nil && !domain.SLAAction(*action).Valid() {
+		return errors.New("sla_action must be REASSIGN or ADD_REVIEWER")
 	}
 	return nil
 }
@@ -448,6 +1192,50 @@ func (r *setUserActiveRequest) validate() error {
 	if strings.TrimSpace(r.UserID) == "" {
 		return errors.New("user_id is required")
 	}
+	if r.ReassignReviews && r.IsActive {
+		return errors.New("reassign_reviews requires is_active to be false")
+	}
+	return nil
+}
+
+func (r *setUserCapacityRequest) validate() error {
+	if strings.TrimSpace(r.UserID) == "" {
+		return errors.New("user_id is required")
+	}
+	if r.MaxOpenReviews == nil {
+		return errors.New("max_open_reviews is required")
+	}
+	if *r.MaxOpenReviews < 0 {
+		return errors.New("max_open_reviews must not be negative")
+	}
+	return nil
+}
+
+func (r *setUserTagsRequest) validate() error {
+	if strings.TrimSpace(r.UserID) == "" {
+		return errors.New("user_id is required")
+	}
+	return validateTags("tags", r.Tags)
+}
+
+func validateTags(field string, tags []string) error {
+	for idx, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			return errors.New(field + "[" + strconv.Itoa(idx) + "] is required")
+		}
+	}
+	return nil
+}
+
+func (r *deactivateTeamMembersRequest) validate() error {
+	if strings.TrimSpace(r.TeamName) == "" {
+		return errors.New("team_name is required")
+	}
+	for idx, id := range r.UserIDs {
+		if strings.TrimSpace(id) == "" {
+			return errors.New("user_ids[" + strconv.Itoa(idx) + "] is required")
+		}
+	}
 	return nil
 }
 
@@ -461,6 +1249,30 @@ func (r *createPullRequestRequest) validate() error {
 	if strings.TrimSpace(r.AuthorID) == "" {
 		return errors.New("author_id is required")
 	}
+	for idx, path := range r.ChangedFiles {
+		if strings.TrimSpace(path) == "" {
+			return errors.New("changed_files[" + strconv.Itoa(idx) + "] is required")
+		}
+	}
+	if _, err := domain.ParsePriority(r.Priority); err != nil {
+		return err
+	}
+	if r.SizeLines < 0 {
+		return errors.New("size_lines must not be negative")
+	}
+	return validateTags("required_tags", r.RequiredTags)
+}
+
+func (r *submitReviewRequest) validate() error {
+	if strings.TrimSpace(r.PullRequestID) == "" {
+		return errors.New("pull_request_id is required")
+	}
+	if strings.TrimSpace(r.ReviewerID) == "" {
+		return errors.New("reviewer_id is required")
+	}
+	if !domain.ReviewState(r.State).IsVerdict() {
+		return errors.New("state must be APPROVED, CHANGES_REQUESTED or COMMENTED")
+	}
 	return nil
 }
 
@@ -471,6 +1283,26 @@ func (r *mergePullRequestRequest) validate() error {
 	return nil
 }
 
+func (r *updatePullRequestRequest) validate() error {
+	if strings.TrimSpace(r.PullRequestID) == "" {
+		return errors.New("pull_request_id is required")
+	}
+	if r.PullRequestName != nil && strings.TrimSpace(*r.PullRequestName) == "" {
+		return errors.New("pull_request_name must not be empty")
+	}
+	if r.AuthorID != nil && strings.TrimSpace(*r.AuthorID) == "" {
+		return errors.New("author_id must not be empty")
+	}
+	return nil
+}
+
+func (r *pullRequestIDRequest) validate() error {
+	if strings.TrimSpace(r.PullRequestID) == "" {
+		return errors.New("pull_request_id is required")
+	}
+	return nil
+}
+
 func (r *reassignReviewerRequest) validate() error {
 	if strings.TrimSpace(r.PullRequestID) == "" {
 		return errors.New("pull_request_id is required")
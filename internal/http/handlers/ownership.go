@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
+)
+
+const maxCodeOwnersSize = 1 << 20
+
+func (h *Handler) listOwnershipRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.svc.ListOwnershipRules(r.Context())
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"rules": mapOwnershipRules(rules),
+	})
+}
+
+func (h *Handler) createOwnershipRule(w http.ResponseWriter, r *http.Request) {
+	var req createOwnershipRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	rule, err := h.svc.CreateOwnershipRule(r.Context(), domain.OwnershipRule{
+		Pattern: req.Pattern,
+		Users:   req.Users,
+		Teams:   req.Teams,
+	})
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSONWithStatus(w, http.StatusCreated, map[string]any{
+		"rule": mapOwnershipRule(rule),
+	})
+}
+
+func (h *Handler) updateOwnershipRule(w http.ResponseWriter, r *http.Request) {
+	var req updateOwnershipRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	rule, err := h.svc.UpdateOwnershipRule(r.Context(), req.RuleID, domain.OwnershipRuleUpdate{
+		Pattern: req.Pattern,
+		Users:   req.Users,
+		Teams:   req.Teams,
+	})
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"rule": mapOwnershipRule(rule),
+	})
+}
+
+func (h *Handler) deleteOwnershipRule(w http.ResponseWriter, r *http.Request) {
+	var req deleteOwnershipRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if req.RuleID <= 0 {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "rule_id is required")
+		return
+	}
+
+	if err := h.svc.DeleteOwnershipRule(r.Context(), req.RuleID); err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"rule_id": req.RuleID,
+		"deleted": true,
+	})
+}
+
+func (h *Handler) uploadCodeOwners(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCodeOwnersSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "failed to read CODEOWNERS file")
+		return
+	}
+
+	rules, err := domain.ParseCodeOwners(string(body))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	stored, err := h.svc.ReplaceOwnershipRules(r.Context(), rules)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"rules": mapOwnershipRules(stored),
+	})
+}
+
+func mapOwnershipRules(rules []domain.OwnershipRule) []map[string]any {
+	out := make([]map[string]any, 0, len(rules))
+	for _, rule := range rules {
+		out = append(out, mapOwnershipRule(rule))
+	}
+	return out
+}
+
+func mapOwnershipRule(rule domain.OwnershipRule) map[string]any {
+	users, teams := rule.Users, rule.Teams
+	if users == nil {
+		users = []string{}
+	}
+	if teams == nil {
+		teams = []string{}
+	}
+	return map[string]any{
+		"rule_id":  rule.ID,
+		"position": rule.Position,
+		"pattern":  rule.Pattern,
+		"users":    users,
+		"teams":    teams,
+	}
+}
+
+type createOwnershipRuleRequest struct {
+	Pattern string   `json:"pattern"`
+	Users   []string `json:"users"`
+	Teams   []string `json:"teams"`
+}
+
+type updateOwnershipRuleRequest struct {
+	RuleID  int64     `json:"rule_id"`
+	Pattern *string   `json:"pattern"`
+	Users   *[]string `json:"users"`
+	Teams   *[]string `json:"teams"`
+}
+
+type deleteOwnershipRuleRequest struct {
+	RuleID int64 `json:"rule_id"`
+}
+
+func (r *createOwnershipRuleRequest) validate() error {
+	if err := domain.ValidateOwnershipPattern(r.Pattern); err != nil {
+		return err
+	}
+	if len(r.Users) == 0 && len(r.Teams) == 0 {
+		return errors.New("users or teams is required")
+	}
+	return validateOwners(r.Users, r.Teams)
+}
+
+func (r *updateOwnershipRuleRequest) validate() error {
+	if r.RuleID <= 0 {
+		return errors.New("rule_id is required")
+	}
+	if r.Pattern != nil {
+		if err := domain.ValidateOwnershipPattern(*r.Pattern); err != nil {
+			return err
+		}
+	}
+	var users, teams []string
+	if r.Users != nil {
+		users = *r.Users
+	}
+	if r.Teams != nil {
+		teams = *r.Teams
+	}
+	return validateOwners(users, teams)
+}
+
+func validateOwners(users, teams []string) error {
+	for idx, id := range users {
+		if strings.TrimSpace(id) == "" {
+			return errors.New("users[" + strconv.Itoa(idx) + "] is required")
+		}
+	}
+	for idx, name := range teams {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("teams[" + strconv.Itoa(idx) + "] is required")
+		}
+	}
+	return nil
+}
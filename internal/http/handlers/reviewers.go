@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+func (h *Handler) addReviewer(w http.ResponseWriter, r *http.Request) {
+	var req reviewerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	pr, err := h.svc.AddReviewer(r.Context(), req.PullRequestID, req.UserID)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"pr": mapPullRequest(pr),
+	})
+}
+
+func (h *Handler) declineReview(w http.ResponseWriter, r *http.Request) {
+	var req declineReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	if !h.checkActor(w, r, req.UserID) {
+		return
+	}
+
+	pr, replacement, err := h.svc.DeclineReview(r.Context(), req.PullRequestID, req.UserID, req.Reason)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	response := map[string]any{
+		"pr": mapPullRequest(pr),
+	}
+	if replacement != "" {
+		response["replaced_by"] = replacement
+	}
+	if pr.Shortfall != nil {
+		response["missing_reviewers"] = mapShortfall(*pr.Shortfall)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) removeReviewer(w http.ResponseWriter, r *http.Request) {
+	var req reviewerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	pr, err := h.svc.RemoveReviewer(r.Context(), req.PullRequestID, req.UserID)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"pr": mapPullRequest(pr),
+	})
+}
+
+func (h *Handler) swapReviewer(w http.ResponseWriter, r *http.Request) {
+	var req swapReviewerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	pr, err := h.svc.SwapReviewer(r.Context(), req.PullRequestID, req.OldUserID, req.NewUserID)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"pr":          mapPullRequest(pr),
+		"replaced_by": req.NewUserID,
+	})
+}
+
+type reviewerRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+}
+
+type declineReviewRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+	Reason        string `json:"reason"`
+}
+
+type swapReviewerRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldUserID     string `json:"old_user_id"`
+	NewUserID     string `json:"new_user_id"`
+}
+
+func (r *reviewerRequest) validate() error {
+	if strings.TrimSpace(r.PullRequestID) == "" {
+		return errors.New("pull_request_id is required")
+	}
+	if strings.TrimSpace(r.UserID) == "" {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
+
+func (r *declineReviewRequest) validate() error {
+	if strings.TrimSpace(r.PullRequestID) == "" {
+		return errors.New("pull_request_id is required")
+	}
+	if strings.TrimSpace(r.UserID) == "" {
+		return errors.New("user_id is required")
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		return errors.New("reason is required")
+	}
+	return nil
+}
+
+func (r *swapReviewerRequest) validate() error {
+	if strings.TrimSpace(r.PullRequestID) == "" {
+		return errors.New("pull_request_id is required")
+	}
+	if strings.TrimSpace(r.OldUserID) == "" {
+		return errors.New("old_user_id is required")
+	}
+	if strings.TrimSpace(r.NewUserID) == "" {
+		return errors.New("new_user_id is required")
+	}
+	if r.OldUserID == r.NewUserID {
+		return errors.New("new_user_id must differ from old_user_id")
+	}
+	return nil
+}
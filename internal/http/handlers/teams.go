@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func (h *Handler) addTeamMembers(w http.ResponseWriter, r *http.Request) {
+	var req addTeamMembersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	team, err := h.svc.AddTeamMembers(r.Context(), req.TeamName, memberUsers(req.TeamName, req.Members), req.AllowMoves)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team": mapTeam(team),
+	})
+}
+
+func (h *Handler) removeTeamMembers(w http.ResponseWriter, r *http.Request) {
+	var req removeTeamMembersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	team, moves, err := h.svc.RemoveTeamMembers(r.Context(), req.TeamName, req.UserIDs, req.ReassignReviews)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team":          mapTeam(team),
+		"reassignments": mapReassignments(moves),
+	})
+}
+
+func (h *Handler) renameTeam(w http.ResponseWriter, r *http.Request) {
+	var req renameTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	team, err := h.svc.RenameTeam(r.Context(), req.TeamName, req.NewTeamName)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team": mapTeam(team),
+	})
+}
+
+func (h *Handler) archiveTeam(w http.ResponseWriter, r *http.Request) {
+	var req archiveTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	team, moves, err := h.svc.ArchiveTeam(r.Context(), req.TeamName, req.ReassignReviews)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team":          mapTeam(team),
+		"reassignments": mapReassignments(moves),
+	})
+}
+
+func (h *Handler) unarchiveTeam(w http.ResponseWriter, r *http.Request) {
+	var req teamNameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	team, err := h.svc.UnarchiveTeam(r.Context(), req.TeamName)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team": mapTeam(team),
+	})
+}
+
+func (h *Handler) deleteTeam(w http.ResponseWriter, r *http.Request) {
+	var req teamNameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	if err := h.svc.DeleteTeam(r.Context(), req.TeamName); err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team_name": req.TeamName,
+		"deleted":   true,
+	})
+}
+
+func (h *Handler) moveUser(w http.ResponseWriter, r *http.Request) {
+	var req moveUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	user, moves, err := h.svc.MoveUser(r.Context(), req.UserID, req.TeamName, req.ReassignReviews)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"user":          mapUser(user),
+		"reassignments": mapReassignments(moves),
+	})
+}
+
+func (h *Handler) joinTeam(w http.ResponseWriter, r *http.Request) {
+	var req joinTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid JSON payload")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	user, err := h.svc.JoinTeam(r.Context(), req.UserID, req.TeamName)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"user": mapUser(user),
+	})
+}
+
+func (h *Handler) listTeamMoves(w http.ResponseWriter, r *http.Request) {
+	userID := strings.TrimSpace(r.URL.Query().Get("user_id"))
+	if userID == "" {
+		writeError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id is required")
+		return
+	}
+
+	moves, err := h.svc.ListTeamMoves(r.Context(), userID)
+	if err != nil {
+		status, code, message := mapDomainError(err)
+		writeError(w, status, code, message)
+		return
+	}
+
+	items := make([]map[string]any, 0, len(moves))
+	for _, move := range moves {
+		item := map[string]any{
+			"move_id":    move.ID,
+			"reassigned": move.Reassigned,
+			"moved_at":   move.MovedAt.UTC(),
+		}
+		if move.FromTeam != "" {
+			item["from_team"] = move.FromTeam
+		}
+		if move.ToTeam != "" {
+			item["to_team"] = move.ToTeam
+		}
+		items = append(items, item)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"user_id": userID,
+		"moves":   items,
+	})
+}
+
+type addTeamMembersRequest struct {
+	TeamName   string              `json:"team_name"`
+	Members    []teamMemberRequest `json:"members"`
+	AllowMoves bool                `json:"allow_moves"`
+}
+
+type removeTeamMembersRequest struct {
+	TeamName        string   `json:"team_name"`
+	UserIDs         []string `json:"user_ids"`
+	ReassignReviews bool     `json:"reassign_reviews"`
+}
+
+type renameTeamRequest struct {
+	TeamName    string `json:"team_name"`
+	NewTeamName string `json:"new_team_name"`
+}
+
+type joinTeamRequest struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+}
+
+type archiveTeamRequest struct {
+	TeamName        string `json:"team_name"`
+	ReassignReviews bool   `json:"reassign_reviews"`
+}
+
+type teamNameRequest struct {
+	TeamName string `json:"team_name"`
+}
+
+type moveUserRequest struct {
+	UserID          string `json:"user_id"`
+	TeamName        string `json:"team_name"`
+	ReassignReviews bool   `json:"reassign_reviews"`
+}
+
+func (r *addTeamMembersRequest) validate() error {
+	if strings.TrimSpace(r.TeamName) == "" {
+		return errors.New("team_name is required")
+	}
+	if len(r.Members) == 0 {
+		return errors.New("members is required")
+	}
+	return validateMembers(r.Members)
+}
+
+func (r *removeTeamMembersRequest) validate() error {
+	if strings.TrimSpace(r.TeamName) == "" {
+		return errors.New("team_name is required")
+	}
+	if len(r.UserIDs) == 0 {
+		return errors.New("user_ids is required")
+	}
+	for idx, id := range r.UserIDs {
+		if strings.TrimSpace(id) == "" {
+			return errors.New("user_ids[" + strconv.Itoa(idx) + "] is required")
+		}
+	}
+	return nil
+}
+
+func (r *renameTeamRequest) validate() error {
+	if strings.TrimSpace(r.TeamName) == "" {
+		return errors.New("team_name is required")
+	}
+	if strings.TrimSpace(r.NewTeamName) == "" {
+		return errors.New("new_team_name is required")
+	}
+	return nil
+}
+
+func (r *archiveTeamRequest) validate() error {
+	if strings.TrimSpace(r.TeamName) == "" {
+		return errors.New("team_name is required")
+	}
+	return nil
+}
+
+func (r *teamNameRequest) validate() error {
+	if strings.TrimSpace(r.TeamName) == "" {
+		return errors.New("team_name is required")
+	}
+	return nil
+}
+
+func (r *moveUserRequest) validate() error {
+	if strings.TrimSpace(r.UserID) == "" {
+		return errors.New("user_id is required")
+	}
+	if strings.TrimSpace(r.TeamName) == "" {
+		return errors.New("team_name is required")
+	}
+	return nil
+}
+
+func (r *joinTeamRequest) validate() error {
+	if strings.TrimSpace(r.UserID) == "" {
+		return errors.New("user_id is required")
+	}
+	if strings.TrimSpace(r.TeamName) == "" {
+		return errors.New("team_name is required")
+	}
+	return nil
+}
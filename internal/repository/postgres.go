@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -52,29 +53,34 @@ func (r *Repository) withTx(ctx context.Context, fn func(pgx.Tx) error) (err err
 	return nil
 }
 
-func (r *Repository) CreateTeam(ctx context.Context, team domain.Team) (domain.Team, error) {
+// CreateTeam creates the team and its members. Members that already belong
+// to another team are moved only with allowMoves, otherwise the whole team is
+// rejected with ErrUserInOtherTeam.
+func (r *Repository) CreateTeam(ctx context.Context, team domain.Team, allowMoves bool) (domain.Team, error) {
 	var out domain.Team
 
 	err := r.withTx(ctx, func(tx pgx.Tx) error {
-		_, err := tx.Exec(ctx, `INSERT INTO teams (team_name) VALUES ($1)`, team.Name)
+		_, err := tx.Exec(ctx, `
+            INSERT INTO teams (team_name, required_reviewers, required_approvals, max_open_reviews, review_sla_hours, sla_action, parent_team)
+            VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+        `, team.Name, team.RequiredReviewers, team.RequiredApprovals, team.MaxOpenReviews, team.ReviewSLAHours, team.SLAAction, team.ParentTeam)
 		if err != nil {
 			var pgErr *pgconn.PgError
 			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
 				return domain.ErrTeamExists
 			}
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return domain.ErrTeamNotFound
+			}
+			return err
+		}
+
+		if err := r.replaceFallbackTeams(ctx, tx, team.Name, team.FallbackTeams); err != nil {
 			return err
 		}
 
 		for _, member := range team.Members {
-			_, err = tx.Exec(ctx, `
-                INSERT INTO users (user_id, username, team_name, is_active)
-                VALUES ($1, $2, $3, $4)
-                ON CONFLICT (user_id) DO UPDATE
-                SET username = EXCLUDED.username,
-                    team_name = EXCLUDED.team_name,
-                    is_active = EXCLUDED.is_active
-            `, member.ID, member.Username, team.Name, member.IsActive)
-			if err != nil {
+			if err := upsertMember(ctx, tx, team.Name, member, allowMoves); err != nil {
 				return err
 			}
 		}
@@ -89,276 +95,1859 @@ func (r *Repository) CreateTeam(ctx context.Context, team domain.Team) (domain.T
 	return r.GetTeam(ctx, team.Name)
 }
 
-func (r *Repository) GetTeam(ctx context.Context, teamName string) (domain.Team, error) {
-	var team domain.Team
-	team.Name = teamName
+// upsertMember adds the user to the team. A user whose primary team is
+// another one is moved there only with allowMove; a user that is already a
+// secondary member of the team keeps the primary team. An existing user keeps
+// their tags when member.Tags is nil and their review limit when
+// member.MaxOpenReviews is 0.
+func upsertMember(ctx context.Context, tx pgx.Tx, teamName string, member domain.User, allowMove bool) error {
+	var current *string
+	err := tx.QueryRow(ctx, `SELECT team_name FROM users WHERE user_id = $1 FOR UPDATE`, member.ID).Scan(&current)
+	existing := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
 
-	var exists string
-	if err := r.pool.QueryRow(ctx, `SELECT team_name FROM teams WHERE team_name = $1`, teamName).Scan(&exists); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return team, domain.ErrTeamNotFound
+	primary := teamName
+	if current != nil && *current != teamName {
+		var inTeam bool
+		if err := tx.QueryRow(ctx, `
+            SELECT EXISTS (SELECT 1 FROM team_members WHERE team_name = $1 AND user_id = $2)
+        `, teamName, member.ID).Scan(&inTeam); err != nil {
+			return err
+		}
+		switch {
+		case inTeam:
+			primary = *current
+		case !allowMove:
+			return fmt.Errorf("user %q is in team %q: %w", member.ID, *current, domain.ErrUserInOtherTeam)
 		}
-		return team, err
 	}
 
-	rows, err := r.pool.Query(ctx, `
-        SELECT user_id, username, is_active
-        FROM users
-        WHERE team_name = $1
-        ORDER BY username ASC
-    `, teamName)
+	_, err = tx.Exec(ctx, `
+        INSERT INTO users (user_id, username, team_name, is_active, tags, max_open_reviews)
+        VALUES ($1, $2, $3, $4, COALESCE($5::text[], '{}'), $6)
+        ON CONFLICT (user_id) DO UPDATE
+        SET username = EXCLUDED.username,
+            team_name = EXCLUDED.team_name,
+            is_active = EXCLUDED.is_active,
+            tags = COALESCE($5::text[], users.tags),
+            max_open_reviews = COALESCE(NULLIF($6, 0), users.max_open_reviews)
+    `, member.ID, member.Username, primary, member.IsActive, member.Tags, member.MaxOpenReviews)
 	if err != nil {
-		return team, err
+		return err
 	}
-	defer rows.Close()
+	if current != nil && *current != primary {
+		if _, err := tx.Exec(ctx, `
+            DELETE FROM team_members WHERE team_name = $1 AND user_id = $2
+        `, *current, member.ID); err != nil {
+			return err
+		}
+	}
+	if err := addMembership(ctx, tx, teamName, member.ID); err != nil {
+		return err
+	}
+	if !existing || (current != nil && *current == primary) {
+		return nil
+	}
+	var from string
+	if current != nil {
+		from = *current
+	}
+	return recordTeamMove(ctx, tx, member.ID, from, primary, 0)
+}
 
-	for rows.Next() {
-		var member domain.User
-		member.TeamName = teamName
-		if err := rows.Scan(&member.ID, &member.Username, &member.IsActive); err != nil {
-			return team, err
+func addMembership(ctx context.Context, tx pgx.Tx, teamName, userID string) error {
+	_, err := tx.Exec(ctx, `
+        INSERT INTO team_members (team_name, user_id) VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+    `, teamName, userID)
+	return err
+}
+
+func recordTeamMove(ctx context.Context, tx pgx.Tx, userID, fromTeam, toTeam string, reassigned int) error {
+	_, err := tx.Exec(ctx, `
+        INSERT INTO team_moves (user_id, from_team, to_team, reassigned)
+        VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4)
+    `, userID, fromTeam, toTeam, reassigned)
+	return err
+}
+
+func (r *Repository) AddTeamMembers(ctx context.Context, teamName string, members []domain.User, allowMoves bool) (domain.Team, error) {
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		archived, err := lockTeam(ctx, tx, teamName)
+		if err != nil {
+			return err
 		}
-		team.Members = append(team.Members, member)
+		if archived {
+			return domain.ErrTeamArchived
+		}
+		for _, member := range members {
+			if err := upsertMember(ctx, tx, teamName, member, allowMoves); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return domain.Team{}, err
+	}
+
+	return r.GetTeam(ctx, teamName)
+}
+
+// lockTeam locks the team row and reports whether the team is archived.
+func lockTeam(ctx context.Context, tx pgx.Tx, teamName string) (bool, error) {
+	var archivedAt *time.Time
+	err := tx.QueryRow(ctx, `SELECT archived_at FROM teams WHERE team_name = $1 FOR UPDATE`, teamName).Scan(&archivedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, domain.ErrTeamNotFound
+	}
+	return archivedAt != nil, err
+}
+
+// RemoveTeamMembers takes the users out of the team. A user whose primary
+// team it was gets the earliest remaining membership as the primary team, or
+// no team at all. moves replace the members' OPEN reviews; a move without a
+// new reviewer only drops the assignment. The removal is blocked by members
+// that author OPEN or DRAFT PRs of the team, or that after the moves still
+// review OPEN PRs of the team, or any OPEN PR when it was their last team.
+func (r *Repository) RemoveTeamMembers(ctx context.Context, teamName string, userIDs []string, moves []domain.ReviewerReassignment) (domain.Team, error) {
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := lockTeam(ctx, tx, teamName); err != nil {
+			return err
+		}
+
+		var authors bool
+		err := tx.QueryRow(ctx, `
+            SELECT EXISTS (
+                SELECT 1 FROM pull_requests
+                WHERE author_id = ANY($1) AND team_name = $2 AND status IN ('OPEN', 'DRAFT')
+            )
+        `, userIDs, teamName).Scan(&authors)
+		if err != nil {
+			return err
+		}
+		if authors {
+			return domain.ErrMemberHasOpenPRs
+		}
+
+		for _, move := range moves {
+			if err := replaceOpenReviewer(ctx, tx, move); err != nil {
+				return err
+			}
+		}
+
+		var reviewing bool
+		err = tx.QueryRow(ctx, `
+            SELECT EXISTS (
+                SELECT 1 FROM pull_request_reviewers prr
+                JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+                WHERE prr.reviewer_id = ANY($1) AND pr.status = 'OPEN'
+                  AND (pr.team_name = $2 OR NOT EXISTS (
+                      SELECT 1 FROM team_members m
+                      WHERE m.user_id = prr.reviewer_id AND m.team_name <> $2
+                  ))
+            )
+        `, userIDs, teamName).Scan(&reviewing)
+		if err != nil {
+			return err
+		}
+		if reviewing {
+			return domain.ErrMemberHasOpenReviews
+		}
+
+		tag, err := tx.Exec(ctx, `
+            DELETE FROM team_members WHERE user_id = ANY($1) AND team_name = $2
+        `, userIDs, teamName)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() != int64(len(userIDs)) {
+			return domain.ErrUserNotFound
+		}
+
+		return promoteMemberships(ctx, tx, userIDs, teamName, moves)
+	})
+
+	if err != nil {
+		return domain.Team{}, err
 	}
 
+	return r.GetTeam(ctx, teamName)
+}
+
+// promoteMemberships gives the users whose primary team was teamName the
+// earliest of their other memberships as the primary team, or none, and
+// records the move.
+func promoteMemberships(ctx context.Context, tx pgx.Tx, userIDs []string, teamName string, moves []domain.ReviewerReassignment) error {
+	rows, err := tx.Query(ctx, `
+        UPDATE users u
+        SET team_name = (
+            SELECT m.team_name FROM team_members m
+            WHERE m.user_id = u.user_id AND m.team_name <> $2
+            ORDER BY m.joined_at ASC, m.team_name ASC
+            LIMIT 1
+        )
+        WHERE u.user_id = ANY($1) AND u.team_name = $2
+        RETURNING u.user_id, COALESCE(u.team_name, '')
+    `, userIDs, teamName)
+	if err != nil {
+		return err
+	}
+	promoted := make(map[string]string)
+	for rows.Next() {
+		var id, primary string
+		if err := rows.Scan(&id, &primary); err != nil {
+			rows.Close()
+			return err
+		}
+		promoted[id] = primary
+	}
+	rows.Close()
 	if err := rows.Err(); err != nil {
-		return team, err
+		return err
 	}
 
-	return team, nil
+	for _, id := range userIDs {
+		primary, ok := promoted[id]
+		if !ok {
+			continue
+		}
+		if err := recordTeamMove(ctx, tx, id, teamName, primary, reassignedCount(moves, id)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (r *Repository) SetUserActivity(ctx context.Context, userID string, isActive bool) (domain.User, error) {
+func reassignedCount(moves []domain.ReviewerReassignment, userID string) int {
+	n := 0
+	for _, move := range moves {
+		if move.OldReviewerID == userID && move.NewReviewerID != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// MoveUser makes another team the user's primary team, replacing the
+// membership of the old one, and records the move. Other memberships stay.
+// moves hand the user's reviews over to other candidates; moves without a
+// replacement leave the user on the PR.
+func (r *Repository) MoveUser(ctx context.Context, userID, teamName string, moves []domain.ReviewerReassignment) (domain.User, domain.TeamMove, error) {
 	var user domain.User
+	var move domain.TeamMove
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		var current *string
+		err := tx.QueryRow(ctx, `SELECT team_name FROM users WHERE user_id = $1 FOR UPDATE`, userID).Scan(&current)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		archived, err := lockTeam(ctx, tx, teamName)
+		if err != nil {
+			return err
+		}
+		if archived {
+			return domain.ErrTeamArchived
+		}
+
+		move = domain.TeamMove{UserID: userID, ToTeam: teamName, Reassigned: reassignedCount(moves, userID)}
+		if current != nil {
+			move.FromTeam = *current
+		}
+		if move.FromTeam == teamName {
+			user, err = r.getUser(ctx, tx, userID)
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE users SET team_name = $2 WHERE user_id = $1`, userID, teamName); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+            DELETE FROM team_members WHERE team_name = $1 AND user_id = $2
+        `, move.FromTeam, userID); err != nil {
+			return err
+		}
+		if err := addMembership(ctx, tx, teamName, userID); err != nil {
+			return err
+		}
+		if err := applyReassignments(ctx, tx, moves); err != nil {
+			return err
+		}
+		err = tx.QueryRow(ctx, `
+            INSERT INTO team_moves (user_id, from_team, to_team, reassigned)
+            VALUES ($1, NULLIF($2, ''), $3, $4)
+            RETURNING move_id, moved_at
+        `, userID, move.FromTeam, teamName, move.Reassigned).Scan(&move.ID, &move.MovedAt)
+		if err != nil {
+			return err
+		}
+
+		user, err = r.getUser(ctx, tx, userID)
+		return err
+	})
 
-	err := r.pool.QueryRow(ctx, `
-        UPDATE users
-        SET is_active = $2
-        WHERE user_id = $1
-        RETURNING user_id, username, team_name, is_active
-    `, userID, isActive).
-		Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive)
 	if err != nil {
+		return domain.User{}, domain.TeamMove{}, err
+	}
+
+	return user, move, nil
+}
+
+// JoinTeam adds the user to one more team. A user without a team gets it as
+// the primary team.
+func (r *Repository) JoinTeam(ctx context.Context, userID, teamName string) (domain.User, error) {
+	var user domain.User
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		var current *string
+		err := tx.QueryRow(ctx, `SELECT team_name FROM users WHERE user_id = $1 FOR UPDATE`, userID).Scan(&current)
 		if errors.Is(err, pgx.ErrNoRows) {
-			return user, domain.ErrUserNotFound
+			return domain.ErrUserNotFound
 		}
-		return user, err
+		if err != nil {
+			return err
+		}
+		archived, err := lockTeam(ctx, tx, teamName)
+		if err != nil {
+			return err
+		}
+		if archived {
+			return domain.ErrTeamArchived
+		}
+
+		if err := addMembership(ctx, tx, teamName, userID); err != nil {
+			return err
+		}
+		if current == nil {
+			if _, err := tx.Exec(ctx, `UPDATE users SET team_name = $2 WHERE user_id = $1`, userID, teamName); err != nil {
+				return err
+			}
+			if err := recordTeamMove(ctx, tx, userID, "", teamName, 0); err != nil {
+				return err
+			}
+		}
+
+		user, err = r.getUser(ctx, tx, userID)
+		return err
+	})
+
+	if err != nil {
+		return domain.User{}, err
 	}
 
 	return user, nil
 }
 
-func (r *Repository) CreatePullRequest(ctx context.Context, id, name, authorID string) (domain.PullRequest, error) {
-	var pr domain.PullRequest
-	author, err := r.getUser(ctx, r.pool, authorID)
+func (r *Repository) ListTeamMoves(ctx context.Context, userID string) ([]domain.TeamMove, error) {
+	rows, err := r.pool.Query(ctx, `
+        SELECT move_id, user_id, COALESCE(from_team, ''), COALESCE(to_team, ''), reassigned, moved_at
+        FROM team_moves
+        WHERE user_id = $1
+        ORDER BY moved_at ASC, move_id ASC
+    `, userID)
 	if err != nil {
-		return pr, err
+		return nil, err
 	}
-	if author.TeamName == "" {
-		return pr, domain.ErrTeamNotFound
+	defer rows.Close()
+
+	var moves []domain.TeamMove
+	for rows.Next() {
+		var move domain.TeamMove
+		if err := rows.Scan(&move.ID, &move.UserID, &move.FromTeam, &move.ToTeam, &move.Reassigned, &move.MovedAt); err != nil {
+			return nil, err
+		}
+		moves = append(moves, move)
 	}
-	reviewerRows, err := r.pool.Query(ctx, `
-        SELECT user_id
-        FROM users
-        WHERE team_name = $1 AND is_active = TRUE AND user_id <> $2
-        ORDER BY random()
-        LIMIT 2
-    `, author.TeamName, authorID)
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return moves, nil
+}
+
+// ArchiveTeam marks the team archived, archiving twice keeps the first
+// timestamp. moves hand the members' OPEN reviews to other candidates.
+func (r *Repository) ArchiveTeam(ctx context.Context, teamName string, moves []domain.ReviewerReassignment) (domain.Team, error) {
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := lockTeam(ctx, tx, teamName); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+            UPDATE teams SET archived_at = COALESCE(archived_at, NOW()) WHERE team_name = $1
+        `, teamName); err != nil {
+			return err
+		}
+		return applyReassignments(ctx, tx, moves)
+	})
+
 	if err != nil {
-		return pr, err
+		return domain.Team{}, err
+	}
+
+	return r.GetTeam(ctx, teamName)
+}
+
+func (r *Repository) UnarchiveTeam(ctx context.Context, teamName string) (domain.Team, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE teams SET archived_at = NULL WHERE team_name = $1`, teamName)
+	if err != nil {
+		return domain.Team{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.Team{}, domain.ErrTeamNotFound
+	}
+	return r.GetTeam(ctx, teamName)
+}
+
+// DeleteTeam removes a team no PR was created for. Members whose primary
+// team it was fall back to another membership or no team, and the team is
+// dropped from fallback lists, reviewer fallback markers and ownership rules;
+// rules left without owners are deleted. Its subteams move up to the team's
+// own parent.
+func (r *Repository) DeleteTeam(ctx context.Context, teamName string) error {
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := lockTeam(ctx, tx, teamName); err != nil {
+			return err
+		}
+
+		var hasPRs bool
+		err := tx.QueryRow(ctx, `
+            SELECT EXISTS (SELECT 1 FROM pull_requests WHERE team_name = $1)
+        `, teamName).Scan(&hasPRs)
+		if err != nil {
+			return err
+		}
+		if hasPRs {
+			return domain.ErrTeamHasPRs
+		}
+
+		rows, err := tx.Query(ctx, `SELECT user_id FROM team_members WHERE team_name = $1`, teamName)
+		if err != nil {
+			return err
+		}
+		var members []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			members = append(members, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM team_members WHERE team_name = $1`, teamName); err != nil {
+			return err
+		}
+		if err := promoteMemberships(ctx, tx, members, teamName, nil); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+            UPDATE pull_request_reviewers SET fallback_team = NULL WHERE fallback_team = $1
+        `, teamName); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+            UPDATE ownership_rules
+            SET owner_teams = array_remove(owner_teams, $1)
+            WHERE $1 = ANY(owner_teams)
+        `, teamName); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+            DELETE FROM ownership_rules
+            WHERE cardinality(owner_users) = 0 AND cardinality(owner_teams) = 0
+        `); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+            UPDATE teams
+            SET parent_team = (SELECT parent_team FROM teams WHERE team_name = $1)
+            WHERE parent_team = $1
+        `, teamName); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `DELETE FROM teams WHERE team_name = $1`, teamName)
+		return err
+	})
+}
+
+// replaceOpenReviewer drops the old reviewer from an OPEN PR and adds the
+// new one when there is one.
+func replaceOpenReviewer(ctx context.Context, tx pgx.Tx, move domain.ReviewerReassignment) error {
+	tag, err := tx.Exec(ctx, `
+        DELETE FROM pull_request_reviewers prr
+        USING pull_requests pr
+        WHERE prr.pull_request_id = pr.pull_request_id
+          AND pr.pull_request_id = $1
+          AND pr.status = 'OPEN'
+          AND prr.reviewer_id = $2
+    `, move.PullRequestID, move.OldReviewerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 || move.NewReviewerID == "" {
+		return nil
 	}
-	defer reviewerRows.Close()
+	return insertReviewer(ctx, tx, move.PullRequestID, move.NewReviewerID, move.FallbackTeam)
+}
+
+// RenameTeam changes the team name; members and fallback links follow via
+// ON UPDATE CASCADE, fallback markers on reviewers and ownership rules are
+// rewritten here.
+func (r *Repository) RenameTeam(ctx context.Context, oldName, newName string) (domain.Team, error) {
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `UPDATE teams SET team_name = $2 WHERE team_name = $1`, oldName, newName)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return domain.ErrTeamExists
+			}
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrTeamNotFound
+		}
+
+		if _, err := tx.Exec(ctx, `
+            UPDATE pull_request_reviewers SET fallback_team = $2 WHERE fallback_team = $1
+        `, oldName, newName); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+            UPDATE ownership_rules
+            SET owner_teams = array_replace(owner_teams, $1, $2)
+            WHERE $1 = ANY(owner_teams)
+        `, oldName, newName)
+		return err
+	})
+
+	if err != nil {
+		return domain.Team{}, err
+	}
+
+	return r.GetTeam(ctx, newName)
+}
+
+func (r *Repository) GetTeam(ctx context.Context, teamName string) (domain.Team, error) {
+	var team domain.Team
+	team.Name = teamName
+
+	err := r.pool.QueryRow(ctx, `
+        SELECT required_reviewers, required_approvals, max_open_reviews, review_sla_hours, sla_action, archived_at,
+               COALESCE(parent_team, '')
+        FROM teams
+        WHERE team_name = $1
+    `, teamName).Scan(&team.RequiredReviewers, &team.RequiredApprovals, &team.MaxOpenReviews, &team.ReviewSLAHours, &team.SLAAction, &team.ArchivedAt, &team.ParentTeam)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return team, domain.ErrTeamNotFound
+		}
+		return team, err
+	}
+
+	fallbacks, err := r.pool.Query(ctx, `
+        SELECT fallback_team_name
+        FROM team_fallbacks
+        WHERE team_name = $1
+        ORDER BY position ASC
+    `, teamName)
+	if err != nil {
+		return team, err
+	}
+	defer fallbacks.Close()
+
+	for fallbacks.Next() {
+		var name string
+		if err := fallbacks.Scan(&name); err != nil {
+			return team, err
+		}
+		team.FallbackTeams = append(team.FallbackTeams, name)
+	}
+
+	if err := fallbacks.Err(); err != nil {
+		return team, err
+	}
+
+	rows, err := r.pool.Query(ctx, `
+        SELECT u.user_id, u.username, COALESCE(u.team_name, ''), u.is_active, u.tags, u.max_open_reviews,
+               ARRAY(SELECT o.team_name FROM team_members o WHERE o.user_id = u.user_id ORDER BY o.joined_at, o.team_name)
+        FROM users u
+        JOIN team_members m ON m.user_id = u.user_id
+        WHERE m.team_name = $1
+        ORDER BY u.username ASC
+    `, teamName)
+	if err != nil {
+		return team, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member domain.User
+		if err := rows.Scan(&member.ID, &member.Username, &member.TeamName, &member.IsActive, &member.Tags, &member.MaxOpenReviews, &member.Teams); err != nil {
+			return team, err
+		}
+		team.Members = append(team.Members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return team, err
+	}
+
+	windows, err := r.pool.Query(ctx, `
+        SELECT w.window_id, w.user_id, w.starts_at, w.ends_at, w.reason
+        FROM user_unavailability w
+        JOIN team_members m ON m.user_id = w.user_id
+        WHERE m.team_name = $1 AND w.ends_at > NOW()
+        ORDER BY w.starts_at ASC, w.window_id ASC
+    `, teamName)
+	if err != nil {
+		return team, err
+	}
+	defer windows.Close()
+
+	byUser := make(map[string][]domain.UnavailabilityWindow)
+	for windows.Next() {
+		var w domain.UnavailabilityWindow
+		if err := windows.Scan(&w.ID, &w.UserID, &w.Start, &w.End, &w.Reason); err != nil {
+			return team, err
+		}
+		byUser[w.UserID] = append(byUser[w.UserID], w)
+	}
+
+	if err := windows.Err(); err != nil {
+		return team, err
+	}
+
+	for i := range team.Members {
+		team.Members[i].Unavailability = byUser[team.Members[i].ID]
+	}
+
+	return team, nil
+}
+
+func (r *Repository) UpdateTeam(ctx context.Context, teamName string, update domain.TeamUpdate) (domain.Team, error) {
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+            UPDATE teams
+            SET required_reviewers = COALESCE($2, required_reviewers),
+                max_open_reviews = COALESCE($3, max_open_reviews),
+                required_approvals = COALESCE($4, required_approvals),
+                review_sla_hours = COALESCE($5, review_sla_hours),
+                sla_action = COALESCE($6, sla_action)
+            WHERE team_name = $1
+        `, teamName, update.RequiredReviewers, update.MaxOpenReviews, update.RequiredApprovals, update.ReviewSLAHours, update.SLAAction)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrTeamNotFound
+		}
+
+		if update.FallbackTeams != nil {
+			if _, err := tx.Exec(ctx, `DELETE FROM team_fallbacks WHERE team_name = $1`, teamName); err != nil {
+				return err
+			}
+			if err := r.replaceFallbackTeams(ctx, tx, teamName, *update.FallbackTeams); err != nil {
+				return err
+			}
+		}
+
+		if update.ParentTeam != nil {
+			if err := setParentTeam(ctx, tx, teamName, *update.ParentTeam); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return domain.Team{}, err
+	}
+
+	return r.GetTeam(ctx, teamName)
+}
+
+// setParentTeam moves the team under parent, or detaches it when parent is
+// empty. The hierarchy is locked for the check so two concurrent moves cannot
+// close a cycle between them.
+func setParentTeam(ctx context.Context, tx pgx.Tx, teamName, parent string) error {
+	if parent != "" {
+		if _, err := tx.Exec(ctx, `LOCK TABLE teams IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			return err
+		}
+		var cycle bool
+		err := tx.QueryRow(ctx, `
+            WITH RECURSIVE ancestors AS (
+                SELECT team_name, parent_team FROM teams WHERE team_name = $2
+                UNION
+                SELECT t.team_name, t.parent_team
+                FROM teams t
+                JOIN ancestors a ON t.team_name = a.parent_team
+            )
+            SELECT EXISTS (SELECT 1 FROM ancestors WHERE team_name = $1)
+        `, teamName, parent).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return domain.ErrTeamCycle
+		}
+	}
+
+	_, err := tx.Exec(ctx, `UPDATE teams SET parent_team = NULLIF($2, '') WHERE team_name = $1`, teamName, parent)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return domain.ErrTeamNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ListSubteams returns the names of the teams placed directly under the team.
+func (r *Repository) ListSubteams(ctx context.Context, teamName string) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+        SELECT team_name FROM teams WHERE parent_team = $1 ORDER BY team_name ASC
+    `, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (r *Repository) replaceFallbackTeams(ctx context.Context, tx pgx.Tx, teamName string, fallbackTeams []string) error {
+	for position, fallback := range fallbackTeams {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO team_fallbacks (team_name, fallback_team_name, position)
+            VALUES ($1, $2, $3)
+        `, teamName, fallback, position)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return domain.ErrTeamNotFound
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) SetUserActivity(ctx context.Context, userID string, isActive bool) (domain.User, error) {
+	var user domain.User
+
+	err := r.pool.QueryRow(ctx, `
+        UPDATE users
+        SET is_active = $2
+        WHERE user_id = $1
+        RETURNING user_id, username, COALESCE(team_name, ''), is_active, tags, max_open_reviews,
+                  ARRAY(SELECT m.team_name FROM team_members m WHERE m.user_id = users.user_id ORDER BY m.joined_at, m.team_name)
+    `, userID, isActive).
+		Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Tags, &user.MaxOpenReviews, &user.Teams)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, domain.ErrUserNotFound
+		}
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (r *Repository) SetUserTags(ctx context.Context, userID string, tags []string) (domain.User, error) {
+	var user domain.User
+
+	err := r.pool.QueryRow(ctx, `
+        UPDATE users
+        SET tags = $2
+        WHERE user_id = $1
+        RETURNING user_id, username, COALESCE(team_name, ''), is_active, tags, max_open_reviews,
+                  ARRAY(SELECT m.team_name FROM team_members m WHERE m.user_id = users.user_id ORDER BY m.joined_at, m.team_name)
+    `, userID, nonNil(tags)).
+		Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Tags, &user.MaxOpenReviews, &user.Teams)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, domain.ErrUserNotFound
+		}
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (r *Repository) AddUnavailability(ctx context.Context, window domain.UnavailabilityWindow) (domain.UnavailabilityWindow, error) {
+	err := r.pool.QueryRow(ctx, `
+        INSERT INTO user_unavailability (user_id, starts_at, ends_at, reason)
+        VALUES ($1, $2, $3, $4)
+        RETURNING window_id
+    `, window.UserID, window.Start, window.End, window.Reason).Scan(&window.ID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return domain.UnavailabilityWindow{}, domain.ErrUserNotFound
+		}
+		return domain.UnavailabilityWindow{}, err
+	}
+
+	return window, nil
+}
+
+func (r *Repository) ListUnavailability(ctx context.Context, userID string) ([]domain.UnavailabilityWindow, error) {
+	if _, err := r.getUser(ctx, r.pool, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `
+        SELECT window_id, user_id, starts_at, ends_at, reason
+        FROM user_unavailability
+        WHERE user_id = $1
+        ORDER BY starts_at ASC, window_id ASC
+    `, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []domain.UnavailabilityWindow
+	for rows.Next() {
+		var w domain.UnavailabilityWindow
+		if err := rows.Scan(&w.ID, &w.UserID, &w.Start, &w.End, &w.Reason); err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return windows, nil
+}
+
+func (r *Repository) DeleteUnavailability(ctx context.Context, userID string, windowID int64) error {
+	tag, err := r.pool.Exec(ctx, `
+        DELETE FROM user_unavailability WHERE window_id = $1 AND user_id = $2
+    `, windowID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWindowNotFound
+	}
+	return nil
+}
+
+// ListAvailableUserIDs keeps the users that can take a review at the given
+// moment: active, not inside any unavailability window and either without
+// teams or a member of at least one team that is not archived.
+func (r *Repository) ListAvailableUserIDs(ctx context.Context, userIDs []string, at time.Time) ([]string, error) {
+	return r.listAvailableUserIDs(ctx, r.pool, userIDs, at)
+}
+
+func (r *Repository) listAvailableUserIDs(ctx context.Context, q querier, userIDs []string, at time.Time) ([]string, error) {
+	rows, err := q.Query(ctx, `
+        SELECT u.user_id
+        FROM users u
+        WHERE u.user_id = ANY($1)
+          AND u.is_active
+          AND NOT EXISTS (
+              SELECT 1 FROM user_unavailability w
+              WHERE w.user_id = u.user_id AND w.starts_at <= $2 AND w.ends_at > $2
+          )
+          AND (
+              NOT EXISTS (SELECT 1 FROM team_members m WHERE m.user_id = u.user_id)
+              OR EXISTS (
+                  SELECT 1 FROM team_members m
+                  JOIN teams t ON t.team_name = m.team_name
+                  WHERE m.user_id = u.user_id AND t.archived_at IS NULL
+              )
+          )
+    `, nonNil(userIDs), at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *Repository) SetUserCapacity(ctx context.Context, userID string, maxOpenReviews int) (domain.User, error) {
+	var user domain.User
+
+	err := r.pool.QueryRow(ctx, `
+        UPDATE users
+        SET max_open_reviews = $2
+        WHERE user_id = $1
+        RETURNING user_id, username, COALESCE(team_name, ''), is_active, tags, max_open_reviews,
+                  ARRAY(SELECT m.team_name FROM team_members m WHERE m.user_id = users.user_id ORDER BY m.joined_at, m.team_name)
+    `, userID, maxOpenReviews).
+		Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Tags, &user.MaxOpenReviews, &user.Teams)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, domain.ErrUserNotFound
+		}
+		return user, err
+	}
+
+	return user, nil
+}
+
+// GetReviewCapacity returns the effective OPEN review limit of every given
+// user that has one: the personal limit, or the default of the user's primary
+// team.
+func (r *Repository) GetReviewCapacity(ctx context.Context, userIDs []string) (map[string]int, error) {
+	return r.getReviewCapacity(ctx, r.pool, userIDs)
+}
+
+func (r *Repository) getReviewCapacity(ctx context.Context, q querier, userIDs []string) (map[string]int, error) {
+	rows, err := q.Query(ctx, `
+        SELECT u.user_id, COALESCE(NULLIF(u.max_open_reviews, 0), t.max_open_reviews, 0)
+        FROM users u
+        LEFT JOIN teams t ON t.team_name = u.team_name
+        WHERE u.user_id = ANY($1)
+          AND COALESCE(NULLIF(u.max_open_reviews, 0), t.max_open_reviews, 0) > 0
+    `, nonNil(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	capacity := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var limit int
+		if err := rows.Scan(&id, &limit); err != nil {
+			return nil, err
+		}
+		capacity[id] = limit
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return capacity, nil
+}
+
+func (r *Repository) GetUser(ctx context.Context, userID string) (domain.User, error) {
+	return r.getUser(ctx, r.pool, userID)
+}
+
+func (r *Repository) GetOpenReviewLoad(ctx context.Context, userIDs []string) (map[string]int, error) {
+	return r.getOpenReviewLoad(ctx, r.pool, userIDs)
+}
+
+func (r *Repository) getOpenReviewLoad(ctx context.Context, q querier, userIDs []string) (map[string]int, error) {
+	rows, err := q.Query(ctx, `
+        SELECT u.user_id, COALESCE(SUM(pr.load_units), 0)
+        FROM users u
+        LEFT JOIN pull_request_reviewers prr ON prr.reviewer_id = u.user_id
+        LEFT JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id AND pr.status = 'OPEN'
+        WHERE u.user_id = ANY($1)
+        GROUP BY u.user_id
+    `, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	load := make(map[string]int)
+	for rows.Next() {
+		var userID string
+		var openReviews int
+		if err := rows.Scan(&userID, &openReviews); err != nil {
+			return nil, err
+		}
+		load[userID] = openReviews
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return load, nil
+}
+
+func (r *Repository) SetUsersActivity(ctx context.Context, userIDs []string, isActive bool, moves []domain.ReviewerReassignment) ([]domain.User, error) {
+	var users []domain.User
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+            UPDATE users
+            SET is_active = $2
+            WHERE user_id = ANY($1)
+            RETURNING user_id, username, COALESCE(team_name, ''), is_active, tags, max_open_reviews,
+                      ARRAY(SELECT m.team_name FROM team_members m WHERE m.user_id = users.user_id ORDER BY m.joined_at, m.team_name)
+        `, userIDs, isActive)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var user domain.User
+			if err := rows.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Tags, &user.MaxOpenReviews, &user.Teams); err != nil {
+				return err
+			}
+			users = append(users, user)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(users) != len(userIDs) {
+			return domain.ErrUserNotFound
+		}
+
+		return applyReassignments(ctx, tx, moves)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// applyReassignments swaps reviewers on PRs that are still OPEN, moves
+// without a replacement are skipped.
+func applyReassignments(ctx context.Context, tx pgx.Tx, moves []domain.ReviewerReassignment) error {
+	for _, move := range moves {
+		if move.NewReviewerID == "" {
+			continue
+		}
+		if err := replaceOpenReviewer(ctx, tx, move); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) ListOpenReviews(ctx context.Context, reviewerIDs []string) ([]domain.PullRequest, error) {
+	rows, err := r.pool.Query(ctx, `
+        SELECT DISTINCT pr.pull_request_id, pr.created_at
+        FROM pull_requests pr
+        JOIN pull_request_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+        WHERE prr.reviewer_id = ANY($1) AND pr.status = 'OPEN'
+        ORDER BY pr.created_at ASC
+    `, reviewerIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		var createdAt time.Time
+		if err := rows.Scan(&id, &createdAt); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return r.loadPullRequests(ctx, ids)
+}
+
+func (r *Repository) ListPendingReviews(ctx context.Context) ([]domain.PendingReview, error) {
+	rows, err := r.pool.Query(ctx, `
+        SELECT pr.pull_request_id, pr.pull_request_name, prr.reviewer_id, t.team_name,
+               t.review_sla_hours, t.sla_action, prr.assigned_at, prr.escalated_at
+        FROM pull_request_reviewers prr
+        JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+        JOIN teams t ON t.team_name = pr.team_name
+        WHERE pr.status = 'OPEN' AND prr.state = 'PENDING' AND t.review_sla_hours > 0
+        ORDER BY prr.assigned_at ASC, pr.pull_request_id ASC, prr.reviewer_id ASC
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pending []domain.PendingReview
+	for rows.Next() {
+		var p domain.PendingReview
+		if err := rows.Scan(&p.PullRequestID, &p.PullRequestName, &p.ReviewerID, &p.TeamName,
+			&p.SLAHours, &p.Action, &p.AssignedAt, &p.EscalatedAt); err != nil {
+			return nil, err
+		}
+		pending = append(pending, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// EscalateReview applies the SLA action to an overdue assignment. It returns
+// ErrNotAssigned when the assignment got a verdict or is gone and
+// ErrAlreadyEscalated when it was escalated before, so the caller can skip it.
+func (r *Repository) EscalateReview(ctx context.Context, escalation domain.SLAEscalation, fallbackTeam string) (domain.PullRequest, error) {
+	var updated domain.PullRequest
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, escalation.PullRequestID); err != nil {
+			return err
+		}
+		pr, err := r.loadPullRequest(ctx, tx, escalation.PullRequestID)
+		if err != nil {
+			return err
+		}
+		if err := pr.CheckOpen(); err != nil {
+			return err
+		}
+
+		review, ok := pr.Reviews[escalation.ReviewerID]
+		if !ok || review.State != domain.ReviewStatePending {
+			return domain.ErrNotAssigned
+		}
+		var escalatedAt *time.Time
+		err = tx.QueryRow(ctx, `
+            SELECT escalated_at FROM pull_request_reviewers
+            WHERE pull_request_id = $1 AND reviewer_id = $2
+        `, escalation.PullRequestID, escalation.ReviewerID).Scan(&escalatedAt)
+		if err != nil {
+			return err
+		}
+		if escalatedAt != nil {
+			return domain.ErrAlreadyEscalated
+		}
+
+		newReviewer, err := r.getUser(ctx, tx, escalation.NewReviewerID)
+		if err != nil {
+			return err
+		}
+		if err := pr.CanAssign(newReviewer); err != nil {
+			return err
+		}
+
+		if escalation.Action == domain.SLAActionReassign {
+			_, err = tx.Exec(ctx,
+				"DELETE FROM pull_request_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2",
+				escalation.PullRequestID, escalation.ReviewerID)
+		} else {
+			_, err = tx.Exec(ctx, `
+                UPDATE pull_request_reviewers SET escalated_at = NOW()
+                WHERE pull_request_id = $1 AND reviewer_id = $2
+            `, escalation.PullRequestID, escalation.ReviewerID)
+		}
+		if err != nil {
+			return err
+		}
+		if err := insertReviewer(ctx, tx, escalation.PullRequestID, escalation.NewReviewerID, fallbackTeam); err != nil {
+			return err
+		}
+
+		updated, err = r.loadPullRequest(ctx, tx, escalation.PullRequestID)
+		return err
+	})
+
+	if err != nil {
+		return updated, err
+	}
+
+	return updated, nil
+}
+
+func (r *Repository) CreatePullRequest(ctx context.Context, pr domain.PullRequest) (domain.PullRequest, error) {
+	if pr.Status == "" {
+		pr.Status = domain.PullRequestStatusOpen
+	}
+	if pr.Priority == "" {
+		pr.Priority = domain.PriorityNormal
+	}
+	pr.CreatedAt = time.Now().UTC()
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status, created_at, changed_files, required_tags,
+                                       priority, size_lines, load_units)
+            VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10, $11)
+        `, pr.ID, pr.Name, pr.AuthorID, pr.TeamName, pr.Status, pr.CreatedAt, nonNil(pr.ChangedFiles), nonNil(pr.RequiredTags),
+			pr.Priority, pr.SizeLines, pr.LoadUnits())
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return domain.ErrPRExists
+			}
+			return err
+		}
+
+		for _, reviewerID := range pr.AssignedReviewers {
+			if err := insertReviewer(ctx, tx, pr.ID, reviewerID, pr.FallbackReviewers[reviewerID]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	return pr, nil
+}
+
+func (r *Repository) GetPullRequest(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return r.loadPullRequest(ctx, r.pool, prID)
+}
+
+func (r *Repository) MergePullRequest(ctx context.Context, prID string, opts domain.MergeOptions) (domain.PullRequest, error) {
+	var result domain.PullRequest
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, prID); err != nil {
+			return err
+		}
+		current, err := r.loadPullRequest(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+
+		if current.Status == domain.PullRequestStatusMerged {
+			result = current
+			return nil
+		}
+		if current.Status == domain.PullRequestStatusClosed {
+			return domain.ErrPRClosed
+		}
+		if current.Status == domain.PullRequestStatusDraft {
+			return domain.ErrPRDraft
+		}
+
+		blocker := current.MergeBlocker()
+		if blocker != nil && !opts.Force {
+			return blocker
+		}
+		if opts.Force {
+			bypassed := ""
+			if blocker != nil {
+				bypassed = blocker.Error()
+			}
+			_, err = tx.Exec(ctx, `
+                INSERT INTO forced_merges (pull_request_id, approvals, required_approvals, bypassed, reason)
+                VALUES ($1, $2, $3, $4, $5)
+            `, prID, current.Approvals(), current.RequiredApprovals, bypassed, opts.Reason)
+			if err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC()
+		_, err = tx.Exec(ctx, `
+            UPDATE pull_requests
+            SET status = $2, merged_at = $3
+            WHERE pull_request_id = $1
+        `, prID, domain.PullRequestStatusMerged, now)
+		if err != nil {
+			return err
+		}
+
+		current.Status = domain.PullRequestStatusMerged
+		current.MergedAt = &now
+		result = current
+
+		return nil
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (r *Repository) ClosePullRequest(ctx context.Context, prID string) (domain.PullRequest, error) {
+	var result domain.PullRequest
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, prID); err != nil {
+			return err
+		}
+		current, err := r.loadPullRequest(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+
+		switch current.Status {
+		case domain.PullRequestStatusClosed:
+			result = current
+			return nil
+		case domain.PullRequestStatusMerged:
+			return domain.ErrPRMerged
+		case domain.PullRequestStatusDraft:
+			return domain.ErrPRDraft
+		}
+
+		now := time.Now().UTC()
+		_, err = tx.Exec(ctx, `
+            UPDATE pull_requests
+            SET status = $2, closed_at = $3
+            WHERE pull_request_id = $1
+        `, prID, domain.PullRequestStatusClosed, now)
+		if err != nil {
+			return err
+		}
+
+		current.Status = domain.PullRequestStatusClosed
+		current.ClosedAt = &now
+		result = current
+
+		return nil
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// UpdatePullRequest changes the PR name and author. moves drop the new author
+// from the reviewers, replacing them when a candidate was found.
+func (r *Repository) UpdatePullRequest(ctx context.Context, prID string, update domain.PullRequestUpdate, moves []domain.ReviewerReassignment) (domain.PullRequest, error) {
+	var result domain.PullRequest
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, prID); err != nil {
+			return err
+		}
+		current, err := r.loadPullRequest(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+
+		switch current.Status {
+		case domain.PullRequestStatusMerged:
+			return domain.ErrPRMerged
+		case domain.PullRequestStatusClosed:
+			return domain.ErrPRClosed
+		}
+
+		_, err = tx.Exec(ctx, `
+            UPDATE pull_requests
+            SET pull_request_name = COALESCE($2, pull_request_name),
+                author_id = COALESCE($3, author_id)
+            WHERE pull_request_id = $1
+        `, prID, update.Name, update.AuthorID)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return domain.ErrUserNotFound
+			}
+			return err
+		}
+
+		for _, move := range moves {
+			_, err := tx.Exec(ctx,
+				"DELETE FROM pull_request_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2",
+				prID, move.OldReviewerID)
+			if err != nil {
+				return err
+			}
+			if move.NewReviewerID == "" {
+				continue
+			}
+			if err := insertReviewer(ctx, tx, prID, move.NewReviewerID, move.FallbackTeam); err != nil {
+				return err
+			}
+		}
+
+		result, err = r.loadPullRequest(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+		// The new author may have been assigned after the service planned
+		// the moves; never leave them reviewing their own PR.
+		if update.AuthorID != nil {
+			for _, reviewerID := range result.AssignedReviewers {
+				if reviewerID == result.AuthorID {
+					return domain.ErrReviewerIsAuthor
+				}
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// MarkPullRequestReady moves a DRAFT PR to OPEN and assigns the reviewers
+// selected for it. A PR that is already OPEN is returned unchanged.
+func (r *Repository) MarkPullRequestReady(ctx context.Context, prID string, reviewerIDs []string, fallbackTeams map[string]string) (domain.PullRequest, error) {
+	var result domain.PullRequest
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, prID); err != nil {
+			return err
+		}
+		current, err := r.loadPullRequest(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+
+		switch current.Status {
+		case domain.PullRequestStatusOpen:
+			result = current
+			return nil
+		case domain.PullRequestStatusMerged:
+			return domain.ErrPRMerged
+		case domain.PullRequestStatusClosed:
+			return domain.ErrPRClosed
+		}
+
+		_, err = tx.Exec(ctx, `
+            UPDATE pull_requests
+            SET status = $2
+            WHERE pull_request_id = $1
+        `, prID, domain.PullRequestStatusOpen)
+		if err != nil {
+			return err
+		}
+
+		for _, reviewerID := range reviewerIDs {
+			if err := insertReviewer(ctx, tx, prID, reviewerID, fallbackTeams[reviewerID]); err != nil {
+				return err
+			}
+		}
+
+		result, err = r.loadPullRequest(ctx, tx, prID)
+		return err
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// ReopenPullRequest moves a CLOSED PR back to OPEN keeping its reviewers and
+// applies the replacements planned for reviewers who left meanwhile.
+func (r *Repository) ReopenPullRequest(ctx context.Context, prID string, moves []domain.ReviewerReassignment) (domain.PullRequest, error) {
+	var result domain.PullRequest
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, prID); err != nil {
+			return err
+		}
+		current, err := r.loadPullRequest(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+
+		switch current.Status {
+		case domain.PullRequestStatusOpen:
+			result = current
+			return nil
+		case domain.PullRequestStatusMerged:
+			return domain.ErrPRMerged
+		case domain.PullRequestStatusDraft:
+			return domain.ErrPRDraft
+		}
+
+		_, err = tx.Exec(ctx, `
+            UPDATE pull_requests
+            SET status = $2, closed_at = NULL
+            WHERE pull_request_id = $1
+        `, prID, domain.PullRequestStatusOpen)
+		if err != nil {
+			return err
+		}
+
+		if err := applyReassignments(ctx, tx, moves); err != nil {
+			return err
+		}
+
+		result, err = r.loadPullRequest(ctx, tx, prID)
+		return err
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (r *Repository) ListForcedMerges(ctx context.Context) ([]domain.ForcedMerge, error) {
+	rows, err := r.pool.Query(ctx, `
+        SELECT merge_id, pull_request_id, forced_at, approvals, required_approvals, bypassed, reason
+        FROM forced_merges
+        ORDER BY forced_at DESC, merge_id DESC
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var merges []domain.ForcedMerge
+	for rows.Next() {
+		var m domain.ForcedMerge
+		if err := rows.Scan(&m.ID, &m.PullRequestID, &m.ForcedAt, &m.Approvals, &m.RequiredApprovals, &m.Bypassed, &m.Reason); err != nil {
+			return nil, err
+		}
+		merges = append(merges, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return merges, nil
+}
+
+func (r *Repository) ReassignReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID, fallbackTeam string) (domain.PullRequest, error) {
+	var updated domain.PullRequest
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, prID); err != nil {
+			return err
+		}
+		pr, err := r.loadPullRequest(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+
+		if err := pr.CheckOpen(); err != nil {
+			return err
+		}
+		newReviewer, err := r.getUser(ctx, tx, newReviewerID)
+		if err != nil {
+			return err
+		}
+		if err := pr.CanAssign(newReviewer); err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx,
+			"DELETE FROM pull_request_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2",
+			prID, oldReviewerID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrNotAssigned
+		}
+
+		if err := insertReviewer(ctx, tx, prID, newReviewerID, fallbackTeam); err != nil {
+			return err
+		}
+
+		updated, err = r.loadPullRequest(ctx, tx, prID)
+		return err
+	})
+
+	if err != nil {
+		return updated, err
+	}
+
+	return updated, nil
+}
+
+func (r *Repository) SubmitReview(ctx context.Context, prID, reviewerID string, state domain.ReviewState) (domain.PullRequest, error) {
+	var updated domain.PullRequest
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, prID); err != nil {
+			return err
+		}
+		pr, err := r.loadPullRequest(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == domain.PullRequestStatusMerged {
+			return domain.ErrPRMerged
+		}
+		if pr.Status == domain.PullRequestStatusClosed {
+			return domain.ErrPRClosed
+		}
+
+		tag, err := tx.Exec(ctx, `
+            UPDATE pull_request_reviewers
+            SET state = $3, submitted_at = NOW()
+            WHERE pull_request_id = $1 AND reviewer_id = $2
+        `, prID, reviewerID, state)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrNotAssigned
+		}
+
+		updated, err = r.loadPullRequest(ctx, tx, prID)
+		return err
+	})
+
+	if err != nil {
+		return updated, err
+	}
+
+	return updated, nil
+}
+
+// DeclineReview removes the reviewer, assigns the replacement when there is
+// one and records the decline in the same transaction.
+func (r *Repository) DeclineReview(ctx context.Context, decline domain.ReviewDecline, fallbackTeam string) (domain.PullRequest, error) {
+	var updated domain.PullRequest
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, decline.PullRequestID); err != nil {
+			return err
+		}
+		pr, err := r.loadPullRequest(ctx, tx, decline.PullRequestID)
+		if err != nil {
+			return err
+		}
 
-	var reviewerIDs []string
-	for reviewerRows.Next() {
-		var reviewerID string
-		if err := reviewerRows.Scan(&reviewerID); err != nil {
-			return pr, err
+		if err := pr.CheckOpen(); err != nil {
+			return err
 		}
-		reviewerIDs = append(reviewerIDs, reviewerID)
-	}
-	if err := reviewerRows.Err(); err != nil {
-		return pr, err
-	}
-	now := time.Now().UTC()
-	err = r.withTx(ctx, func(tx pgx.Tx) error {
-		_, err = tx.Exec(ctx, `
-            INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at)
-            VALUES ($1, $2, $3, $4, $5)
-        `, id, name, authorID, domain.PullRequestStatusOpen, now)
+
+		tag, err := tx.Exec(ctx,
+			"DELETE FROM pull_request_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2",
+			decline.PullRequestID, decline.UserID)
 		if err != nil {
-			var pgErr *pgconn.PgError
-			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-				return domain.ErrPRExists
-			}
 			return err
 		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrNotAssigned
+		}
 
-		for _, reviewerID := range reviewerIDs {
-			_, err = tx.Exec(ctx, `
-                INSERT INTO pull_request_reviewers (pull_request_id, reviewer_id)
-                VALUES ($1, $2)
-            `, id, reviewerID)
-			if err != nil {
+		if decline.ReplacedBy != "" {
+			if err := insertReviewer(ctx, tx, decline.PullRequestID, decline.ReplacedBy, fallbackTeam); err != nil {
 				return err
 			}
 		}
 
-		return nil
+		_, err = tx.Exec(ctx, `
+            INSERT INTO review_declines (pull_request_id, user_id, replaced_by, reason)
+            VALUES ($1, $2, NULLIF($3, ''), $4)
+        `, decline.PullRequestID, decline.UserID, decline.ReplacedBy, decline.Reason)
+		if err != nil {
+			return err
+		}
+
+		updated, err = r.loadPullRequest(ctx, tx, decline.PullRequestID)
+		return err
 	})
 
 	if err != nil {
-		return pr, err
+		return updated, err
 	}
-	pr.ID = id
-	pr.Name = name
-	pr.AuthorID = authorID
-	pr.Status = domain.PullRequestStatusOpen
-	pr.CreatedAt = now
-	pr.AssignedReviewers = reviewerIDs
-
-	return pr, nil
-}
 
-func (r *Repository) GetPullRequest(ctx context.Context, prID string) (domain.PullRequest, error) {
-	return r.loadPullRequest(ctx, r.pool, prID)
+	return updated, nil
 }
 
-func (r *Repository) MergePullRequest(ctx context.Context, prID string) (domain.PullRequest, error) {
-	var result domain.PullRequest
+func (r *Repository) RemoveReviewer(ctx context.Context, prID, reviewerID string) (domain.PullRequest, error) {
+	var updated domain.PullRequest
 
 	err := r.withTx(ctx, func(tx pgx.Tx) error {
-		current, err := r.loadPullRequest(ctx, tx, prID)
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, prID); err != nil {
+			return err
+		}
+		pr, err := r.loadPullRequest(ctx, tx, prID)
 		if err != nil {
 			return err
 		}
 
-		if current.Status == domain.PullRequestStatusMerged {
-			result = current
-			return nil
+		if err := pr.CheckOpen(); err != nil {
+			return err
 		}
 
-		now := time.Now().UTC()
-		_, err = tx.Exec(ctx, `
-            UPDATE pull_requests
-            SET status = $2, merged_at = $3
-            WHERE pull_request_id = $1
-        `, prID, domain.PullRequestStatusMerged, now)
+		tag, err := tx.Exec(ctx,
+			"DELETE FROM pull_request_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2",
+			prID, reviewerID)
 		if err != nil {
 			return err
 		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrNotAssigned
+		}
 
-		current.Status = domain.PullRequestStatusMerged
-		current.MergedAt = &now
-		result = current
-
-		return nil
+		updated, err = r.loadPullRequest(ctx, tx, prID)
+		return err
 	})
 
 	if err != nil {
-		return result, err
+		return updated, err
 	}
 
-	return result, nil
+	return updated, nil
 }
 
-func (r *Repository) ReassignReviewer(ctx context.Context, prID, oldReviewerID string) (domain.PullRequest, string, error) {
+// AddReviewers assigns the given reviewers after re-checking, under the PR
+// row lock, every rule the caller checked before: the PR is OPEN, each
+// reviewer can be assigned, is available and has room for the review. A
+// positive maxReviewers caps the number of reviewers the PR may end up with;
+// exceeding it returns ErrEnoughReviewers.
+func (r *Repository) AddReviewers(ctx context.Context, prID string, reviewerIDs []string, fallbackTeams map[string]string, maxReviewers int) (domain.PullRequest, error) {
 	var updated domain.PullRequest
-	var replacement string
 
-	pr, err := r.loadPullRequest(ctx, r.pool, prID)
-	if err != nil {
-		return updated, "", err
-	}
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, prID); err != nil {
+			return err
+		}
+		pr, err := r.loadPullRequest(ctx, tx, prID)
+		if err != nil {
+			return err
+		}
 
-	if pr.Status == domain.PullRequestStatusMerged {
-		return updated, "", domain.ErrPRMerged
-	}
+		if err := pr.CheckOpen(); err != nil {
+			return err
+		}
+		if maxReviewers > 0 && len(pr.AssignedReviewers)+len(reviewerIDs) > maxReviewers {
+			return domain.ErrEnoughReviewers
+		}
+		if err := r.checkAssignable(ctx, tx, pr, reviewerIDs); err != nil {
+			return err
+		}
 
-	assigned := false
-	assignedSet := make(map[string]struct{}, len(pr.AssignedReviewers))
-	for _, id := range pr.AssignedReviewers {
-		assignedSet[id] = struct{}{}
-		if id == oldReviewerID {
-			assigned = true
+		for _, reviewerID := range reviewerIDs {
+			tag, err := tx.Exec(ctx, `
+                INSERT INTO pull_request_reviewers (pull_request_id, reviewer_id, fallback_team)
+                VALUES ($1, $2, NULLIF($3, ''))
+                ON CONFLICT DO NOTHING
+            `, prID, reviewerID, fallbackTeams[reviewerID])
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return domain.ErrAlreadyAssigned
+			}
 		}
-	}
 
-	if !assigned {
-		return updated, "", domain.ErrNotAssigned
-	}
+		updated, err = r.loadPullRequest(ctx, tx, prID)
+		return err
+	})
 
-	reviewer, err := r.getUser(ctx, r.pool, oldReviewerID)
 	if err != nil {
-		return updated, "", err
-	}
-
-	if reviewer.TeamName == "" {
-		return updated, "", domain.ErrTeamNotFound
+		return updated, err
 	}
 
-	candidates, err := r.pool.Query(ctx, `
-        SELECT user_id
-        FROM users
-        WHERE team_name = $1 
-          AND is_active = TRUE 
-          AND user_id <> $2 
-          AND user_id <> $3
-        ORDER BY random()
-    `, reviewer.TeamName, oldReviewerID, pr.AuthorID)
-	if err != nil {
-		return updated, "", err
-	}
-	defer candidates.Close()
+	return updated, nil
+}
 
-	for candidates.Next() {
-		var candidate string
-		if err := candidates.Scan(&candidate); err != nil {
-			return updated, "", err
+// checkAssignable repeats the reviewer checks of the service inside the
+// transaction, so a reviewer who changed since the selection is not added.
+func (r *Repository) checkAssignable(ctx context.Context, tx pgx.Tx, pr domain.PullRequest, reviewerIDs []string) error {
+	for _, reviewerID := range reviewerIDs {
+		reviewer, err := r.getUser(ctx, tx, reviewerID)
+		if err != nil {
+			return err
 		}
-		if _, exists := assignedSet[candidate]; exists {
-			continue
+		if err := pr.CanAssign(reviewer); err != nil {
+			return err
 		}
-		replacement = candidate
-		break
 	}
 
-	if err := candidates.Err(); err != nil {
-		return updated, "", err
+	available, err := r.listAvailableUserIDs(ctx, tx, reviewerIDs, time.Now())
+	if err != nil {
+		return err
 	}
-
-	if replacement == "" {
-		return updated, "", domain.ErrNoCandidate
+	availableSet := make(map[string]struct{}, len(available))
+	for _, id := range available {
+		availableSet[id] = struct{}{}
 	}
-
-	err = r.withTx(ctx, func(tx pgx.Tx) error {
-		_, err := tx.Exec(ctx,
-			"DELETE FROM pull_request_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2",
-			prID, oldReviewerID)
-		if err != nil {
-			return err
+	for _, reviewerID := range reviewerIDs {
+		if _, ok := availableSet[reviewerID]; !ok {
+			return domain.ErrUserUnavailable
 		}
+	}
 
-		_, err = tx.Exec(ctx,
-			"INSERT INTO pull_request_reviewers (pull_request_id, reviewer_id) VALUES ($1, $2)",
-			prID, replacement)
-		if err != nil {
-			return err
+	load, err := r.getOpenReviewLoad(ctx, tx, reviewerIDs)
+	if err != nil {
+		return err
+	}
+	capacity, err := r.getReviewCapacity(ctx, tx, reviewerIDs)
+	if err != nil {
+		return err
+	}
+	for _, reviewerID := range reviewerIDs {
+		if limit, ok := capacity[reviewerID]; ok && domain.ExceedsCapacity(load[reviewerID], pr.LoadUnits(), limit) {
+			return domain.ErrAtCapacity
 		}
+	}
+	return nil
+}
 
-		return nil
-	})
-
+func (r *Repository) ListUnderstaffedPullRequests(ctx context.Context) ([]domain.PullRequest, error) {
+	rows, err := r.pool.Query(ctx, `
+        SELECT pr.pull_request_id
+        FROM pull_requests pr
+        JOIN teams t ON t.team_name = pr.team_name
+        LEFT JOIN pull_request_reviewers prr ON prr.pull_request_id = pr.pull_request_id
+        WHERE pr.status = 'OPEN'
+        GROUP BY pr.pull_request_id, pr.created_at, t.required_reviewers
+        HAVING COUNT(prr.reviewer_id) < t.required_reviewers
+        ORDER BY pr.created_at ASC
+    `)
 	if err != nil {
-		return updated, "", err
+		return nil, err
 	}
+	defer rows.Close()
 
-	updated, err = r.loadPullRequest(ctx, r.pool, prID)
-	if err != nil {
-		return updated, "", err
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
 	}
 
-	return updated, replacement, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return r.loadPullRequests(ctx, ids)
 }
 
 func (r *Repository) ListReviewerPullRequests(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
@@ -390,13 +1979,95 @@ func (r *Repository) ListReviewerPullRequests(ctx context.Context, userID string
 	return result, nil
 }
 
+func (r *Repository) ListUsersByIDsOrTeams(ctx context.Context, userIDs, teamNames []string) ([]domain.User, error) {
+	rows, err := r.pool.Query(ctx, `
+        SELECT user_id, username, COALESCE(team_name, ''), is_active, tags, max_open_reviews,
+               ARRAY(SELECT m.team_name FROM team_members m WHERE m.user_id = users.user_id ORDER BY m.joined_at, m.team_name)
+        FROM users
+        WHERE user_id = ANY($1) OR EXISTS (
+            SELECT 1 FROM team_members m WHERE m.user_id = users.user_id AND m.team_name = ANY($2)
+        )
+        ORDER BY user_id
+    `, nonNil(userIDs), nonNil(teamNames))
+	if err != nil {
+		return nil, err
+	}
+	return collectUsers(rows)
+}
+
+// ListCodeOwners resolves owner users and owner teams like
+// ListUsersByIDsOrTeams, but skips archived owner teams and, like
+// ListAvailableUserIDs, users whose every team is archived.
+func (r *Repository) ListCodeOwners(ctx context.Context, userIDs, teamNames []string) ([]domain.User, error) {
+	rows, err := r.pool.Query(ctx, `
+        SELECT user_id, username, COALESCE(team_name, ''), is_active, tags, max_open_reviews,
+               ARRAY(SELECT m.team_name FROM team_members m WHERE m.user_id = users.user_id ORDER BY m.joined_at, m.team_name)
+        FROM users
+        WHERE (
+              user_id = ANY($1) OR EXISTS (
+                  SELECT 1 FROM team_members m
+                  JOIN teams t ON t.team_name = m.team_name
+                  WHERE m.user_id = users.user_id AND m.team_name = ANY($2) AND t.archived_at IS NULL
+              )
+          )
+          AND (
+              NOT EXISTS (SELECT 1 FROM team_members m WHERE m.user_id = users.user_id)
+              OR EXISTS (
+                  SELECT 1 FROM team_members m
+                  JOIN teams t ON t.team_name = m.team_name
+                  WHERE m.user_id = users.user_id AND t.archived_at IS NULL
+              )
+          )
+        ORDER BY user_id
+    `, nonNil(userIDs), nonNil(teamNames))
+	if err != nil {
+		return nil, err
+	}
+	return collectUsers(rows)
+}
+
+func collectUsers(rows pgx.Rows) ([]domain.User, error) {
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Tags, &user.MaxOpenReviews, &user.Teams); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func insertReviewer(ctx context.Context, tx pgx.Tx, prID, reviewerID, fallbackTeam string) error {
+	_, err := tx.Exec(ctx, `
+        INSERT INTO pull_request_reviewers (pull_request_id, reviewer_id, fallback_team)
+        VALUES ($1, $2, NULLIF($3, ''))
+    `, prID, reviewerID, fallbackTeam)
+	return err
+}
+
 func (r *Repository) getUser(ctx context.Context, q querier, userID string) (domain.User, error) {
 	var user domain.User
 	err := q.QueryRow(ctx, `
-        SELECT user_id, username, team_name, is_active
+        SELECT user_id, username, COALESCE(team_name, ''), is_active, tags, max_open_reviews,
+               ARRAY(SELECT m.team_name FROM team_members m WHERE m.user_id = users.user_id ORDER BY m.joined_at, m.team_name)
         FROM users
         WHERE user_id = $1
-    `, userID).Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive)
+    `, userID).Scan(&user.ID, &user.Username, &user.TeamName, &user.IsActive, &user.Tags, &user.MaxOpenReviews, &user.Teams)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return user, domain.ErrUserNotFound
@@ -411,10 +2082,12 @@ func (r *Repository) loadPullRequest(ctx context.Context, q querier, prID string
 	var mergedAt *time.Time
 
 	err := q.QueryRow(ctx, `
-        SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
-        FROM pull_requests
-        WHERE pull_request_id = $1
-    `, prID).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &mergedAt)
+        SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, COALESCE(pr.team_name, ''), pr.status, pr.created_at, pr.merged_at, pr.closed_at,
+               COALESCE(t.required_reviewers, 0), COALESCE(t.required_approvals, 0), pr.changed_files, pr.required_tags, pr.priority, pr.size_lines
+        FROM pull_requests pr
+        LEFT JOIN teams t ON t.team_name = pr.team_name
+        WHERE pr.pull_request_id = $1
+    `, prID).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.TeamName, &pr.Status, &pr.CreatedAt, &mergedAt, &pr.ClosedAt, &pr.RequiredReviewers, &pr.RequiredApprovals, &pr.ChangedFiles, &pr.RequiredTags, &pr.Priority, &pr.SizeLines)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return pr, domain.ErrPRNotFound
@@ -424,7 +2097,7 @@ func (r *Repository) loadPullRequest(ctx context.Context, q querier, prID string
 	pr.MergedAt = mergedAt
 
 	rows, err := q.Query(ctx, `
-        SELECT reviewer_id
+        SELECT reviewer_id, fallback_team, state, assigned_at, submitted_at
         FROM pull_request_reviewers
         WHERE pull_request_id = $1
         ORDER BY reviewer_id
@@ -436,10 +2109,22 @@ func (r *Repository) loadPullRequest(ctx context.Context, q querier, prID string
 
 	for rows.Next() {
 		var reviewerID string
-		if err := rows.Scan(&reviewerID); err != nil {
+		var fallbackTeam *string
+		var review domain.Review
+		if err := rows.Scan(&reviewerID, &fallbackTeam, &review.State, &review.AssignedAt, &review.SubmittedAt); err != nil {
 			return pr, err
 		}
 		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewerID)
+		if pr.Reviews == nil {
+			pr.Reviews = make(map[string]domain.Review)
+		}
+		pr.Reviews[reviewerID] = review
+		if fallbackTeam != nil {
+			if pr.FallbackReviewers == nil {
+				pr.FallbackReviewers = make(map[string]string)
+			}
+			pr.FallbackReviewers[reviewerID] = *fallbackTeam
+		}
 	}
 
 	if err := rows.Err(); err != nil {
@@ -449,31 +2134,225 @@ func (r *Repository) loadPullRequest(ctx context.Context, q querier, prID string
 	return pr, nil
 }
 
+func (r *Repository) loadPullRequests(ctx context.Context, ids []string) ([]domain.PullRequest, error) {
+	result := make([]domain.PullRequest, 0, len(ids))
+	for _, id := range ids {
+		pr, err := r.loadPullRequest(ctx, r.pool, id)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, pr)
+	}
+	return result, nil
+}
+
+func (r *Repository) ListOwnershipRules(ctx context.Context) ([]domain.OwnershipRule, error) {
+	rows, err := r.pool.Query(ctx, `
+        SELECT rule_id, position, pattern, owner_users, owner_teams
+        FROM ownership_rules
+        ORDER BY position ASC, rule_id ASC
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []domain.OwnershipRule
+	for rows.Next() {
+		var rule domain.OwnershipRule
+		if err := rows.Scan(&rule.ID, &rule.Position, &rule.Pattern, &rule.Users, &rule.Teams); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (r *Repository) GetOwnershipRule(ctx context.Context, ruleID int64) (domain.OwnershipRule, error) {
+	var rule domain.OwnershipRule
+	err := r.pool.QueryRow(ctx, `
+        SELECT rule_id, position, pattern, owner_users, owner_teams
+        FROM ownership_rules
+        WHERE rule_id = $1
+    `, ruleID).Scan(&rule.ID, &rule.Position, &rule.Pattern, &rule.Users, &rule.Teams)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.OwnershipRule{}, domain.ErrOwnershipRuleNotFound
+	}
+	if err != nil {
+		return domain.OwnershipRule{}, err
+	}
+	return rule, nil
+}
+
+func (r *Repository) CreateOwnershipRule(ctx context.Context, rule domain.OwnershipRule) (domain.OwnershipRule, error) {
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		if err := checkOwnersExist(ctx, tx, rule.Users, rule.Teams); err != nil {
+			return err
+		}
+		return tx.QueryRow(ctx, `
+            INSERT INTO ownership_rules (position, pattern, owner_users, owner_teams)
+            SELECT COALESCE(MAX(position) + 1, 0), $1, $2, $3 FROM ownership_rules
+            RETURNING rule_id, position
+        `, rule.Pattern, nonNil(rule.Users), nonNil(rule.Teams)).Scan(&rule.ID, &rule.Position)
+	})
+
+	if err != nil {
+		return domain.OwnershipRule{}, err
+	}
+
+	return rule, nil
+}
+
+func (r *Repository) UpdateOwnershipRule(ctx context.Context, ruleID int64, update domain.OwnershipRuleUpdate) (domain.OwnershipRule, error) {
+	var rule domain.OwnershipRule
+
+	err := r.withTx(ctx, func(tx pgx.Tx) error {
+		var users, teams []string
+		if update.Users != nil {
+			users = nonNil(*update.Users)
+		}
+		if update.Teams != nil {
+			teams = nonNil(*update.Teams)
+		}
+		if err := checkOwnersExist(ctx, tx, users, teams); err != nil {
+			return err
+		}
+
+		err := tx.QueryRow(ctx, `
+            UPDATE ownership_rules
+            SET pattern = COALESCE($2, pattern),
+                owner_users = COALESCE($3, owner_users),
+                owner_teams = COALESCE($4, owner_teams)
+            WHERE rule_id = $1
+            RETURNING rule_id, position, pattern, owner_users, owner_teams
+        `, ruleID, update.Pattern, users, teams).
+			Scan(&rule.ID, &rule.Position, &rule.Pattern, &rule.Users, &rule.Teams)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrOwnershipRuleNotFound
+		}
+		return err
+	})
+
+	if err != nil {
+		return domain.OwnershipRule{}, err
+	}
+
+	return rule, nil
+}
+
+func (r *Repository) DeleteOwnershipRule(ctx context.Context, ruleID int64) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM ownership_rules WHERE rule_id = $1`, ruleID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrOwnershipRuleNotFound
+	}
+	return nil
+}
+
+func (r *Repository) ReplaceOwnershipRules(ctx context.Context, rules []domain.OwnershipRule) error {
+	return r.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM ownership_rules`); err != nil {
+			return err
+		}
+
+		for position, rule := range rules {
+			if err := checkOwnersExist(ctx, tx, rule.Users, rule.Teams); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, `
+                INSERT INTO ownership_rules (position, pattern, owner_users, owner_teams)
+                VALUES ($1, $2, $3, $4)
+            `, position, rule.Pattern, nonNil(rule.Users), nonNil(rule.Teams))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func checkOwnersExist(ctx context.Context, q querier, users, teams []string) error {
+	for _, userID := range users {
+		var exists bool
+		if err := q.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE user_id = $1)`, userID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("owner %q: %w", userID, domain.ErrUserNotFound)
+		}
+	}
+	for _, teamName := range teams {
+		var exists bool
+		if err := q.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM teams WHERE team_name = $1)`, teamName).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("owner %q: %w", teamName, domain.ErrTeamNotFound)
+		}
+	}
+	return nil
+}
+
 type ReviewerStats struct {
 	UserID           string
 	Username         string
 	TotalAssignments int
+	Declines         int
+}
+
+// DeclineRate is the share of assignments the reviewer declined. Declined
+// assignments are no longer counted in TotalAssignments.
+func (s ReviewerStats) DeclineRate() float64 {
+	offered := s.TotalAssignments + s.Declines
+	if offered == 0 {
+		return 0
+	}
+	return float64(s.Declines) / float64(offered)
 }
 
 type PRStats struct {
 	TotalPRs            int
 	OpenPRs             int
 	MergedPRs           int
+	ClosedPRs           int
+	DraftPRs            int
 	PRsWithReviewers    int
 	PRsWithoutReviewers int
 }
 
-func (r *Repository) GetReviewerStats(ctx context.Context) ([]ReviewerStats, error) {
+// GetReviewerStats counts assignments and declines per user. With teams
+// given, only their members are listed and only PRs created for those teams
+// are counted, so a member of several teams is not credited with reviews of
+// the others.
+func (r *Repository) GetReviewerStats(ctx context.Context, teamNames []string) ([]ReviewerStats, error) {
 	rows, err := r.pool.Query(ctx, `
         SELECT 
             u.user_id,
             u.username,
-            COUNT(prr.reviewer_id) as total_assignments
+            (SELECT COUNT(*)
+             FROM pull_request_reviewers prr
+             JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+             WHERE prr.reviewer_id = u.user_id
+               AND (cardinality($1::text[]) = 0 OR pr.team_name = ANY($1))) as total_assignments,
+            (SELECT COUNT(*)
+             FROM review_declines d
+             JOIN pull_requests pr ON pr.pull_request_id = d.pull_request_id
+             WHERE d.user_id = u.user_id
+               AND (cardinality($1::text[]) = 0 OR pr.team_name = ANY($1))) as declines
         FROM users u
-        LEFT JOIN pull_request_reviewers prr ON u.user_id = prr.reviewer_id
-        GROUP BY u.user_id, u.username
+        WHERE cardinality($1::text[]) = 0 OR EXISTS (
+            SELECT 1 FROM team_members m WHERE m.user_id = u.user_id AND m.team_name = ANY($1)
+        )
         ORDER BY total_assignments DESC, u.username ASC
-    `)
+    `, nonNil(teamNames))
 	if err != nil {
 		return nil, err
 	}
@@ -482,7 +2361,7 @@ func (r *Repository) GetReviewerStats(ctx context.Context) ([]ReviewerStats, err
 	var stats []ReviewerStats
 	for rows.Next() {
 		var s ReviewerStats
-		if err := rows.Scan(&s.UserID, &s.Username, &s.TotalAssignments); err != nil {
+		if err := rows.Scan(&s.UserID, &s.Username, &s.TotalAssignments, &s.Declines); err != nil {
 			return nil, err
 		}
 		stats = append(stats, s)
@@ -495,7 +2374,9 @@ func (r *Repository) GetReviewerStats(ctx context.Context) ([]ReviewerStats, err
 	return stats, nil
 }
 
-func (r *Repository) GetPRStats(ctx context.Context) (PRStats, error) {
+// GetPRStats counts PRs by status, limited to PRs created for the given
+// teams unless the list is empty.
+func (r *Repository) GetPRStats(ctx context.Context, teamNames []string) (PRStats, error) {
 	var stats PRStats
 
 	err := r.pool.QueryRow(ctx, `
@@ -503,6 +2384,8 @@ func (r *Repository) GetPRStats(ctx context.Context) (PRStats, error) {
             COUNT(*) as total,
             COUNT(*) FILTER (WHERE status = 'OPEN') as open,
             COUNT(*) FILTER (WHERE status = 'MERGED') as merged,
+            COUNT(*) FILTER (WHERE status = 'CLOSED') as closed,
+            COUNT(*) FILTER (WHERE status = 'DRAFT') as draft,
             COUNT(*) FILTER (WHERE EXISTS (
                 SELECT 1 FROM pull_request_reviewers prr 
                 WHERE prr.pull_request_id = pr.pull_request_id
@@ -512,10 +2395,13 @@ func (r *Repository) GetPRStats(ctx context.Context) (PRStats, error) {
                 WHERE prr.pull_request_id = pr.pull_request_id
             )) as without_reviewers
         FROM pull_requests pr
-    `).Scan(
+        WHERE cardinality($1::text[]) = 0 OR pr.team_name = ANY($1)
+    `, nonNil(teamNames)).Scan(
 		&stats.TotalPRs,
 		&stats.OpenPRs,
 		&stats.MergedPRs,
+		&stats.ClosedPRs,
+		&stats.DraftPRs,
 		&stats.PRsWithReviewers,
 		&stats.PRsWithoutReviewers,
 	)
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
+)
+
+func (s *service) AddUnavailability(ctx context.Context, window domain.UnavailabilityWindow) (domain.UnavailabilityWindow, error) {
+	if strings.TrimSpace(window.UserID) == "" {
+		log.Printf("[Service] AddUnavailability: validation error - user ID is required")
+		return domain.UnavailabilityWindow{}, errors.New("user ID is required")
+	}
+	if !window.End.After(window.Start) {
+		log.Printf("[Service] AddUnavailability: validation error - window %s..%s is empty", window.Start, window.End)
+		return domain.UnavailabilityWindow{}, domain.ErrInvalidWindow
+	}
+	created, err := s.repo.AddUnavailability(ctx, window)
+	if err != nil {
+		log.Printf("[Service] AddUnavailability: failed to add window for user %q: %v", window.UserID, err)
+		return domain.UnavailabilityWindow{}, fmt.Errorf("failed to add unavailability: %w", err)
+	}
+	log.Printf("[Service] AddUnavailability: user %q unavailable from %s to %s", created.UserID, created.Start, created.End)
+	return created, nil
+}
+
+func (s *service) ListUnavailability(ctx context.Context, userID string) ([]domain.UnavailabilityWindow, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, errors.New("user ID is required")
+	}
+	windows, err := s.repo.ListUnavailability(ctx, userID)
+	if err != nil {
+		log.Printf("[Service] ListUnavailability: failed to list windows for user %q: %v", userID, err)
+		return nil, fmt.Errorf("failed to list unavailability: %w", err)
+	}
+	return windows, nil
+}
+
+func (s *service) DeleteUnavailability(ctx context.Context, userID string, windowID int64) error {
+	if strings.TrimSpace(userID) == "" {
+		return errors.New("user ID is required")
+	}
+	if err := s.repo.DeleteUnavailability(ctx, userID, windowID); err != nil {
+		log.Printf("[Service] DeleteUnavailability: failed to delete window %d of user %q: %v", windowID, userID, err)
+		return fmt.Errorf("failed to delete unavailability: %w", err)
+	}
+	log.Printf("[Service] DeleteUnavailability: deleted window %d of user %q", windowID, userID)
+	return nil
+}
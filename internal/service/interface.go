@@ -2,20 +2,57 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
 	"github.com/dangy/pr-reviewer-assignment-service/internal/repository"
 )
 
 type Service interface {
-	CreateTeam(ctx context.Context, team domain.Team) (domain.Team, error)
+	CreateTeam(ctx context.Context, team domain.Team, allowMoves bool) (domain.Team, error)
 	GetTeam(ctx context.Context, teamName string) (domain.Team, error)
+	GetTeamTree(ctx context.Context, teamName string) (domain.Team, error)
+	UpdateTeam(ctx context.Context, teamName string, update domain.TeamUpdate) (domain.Team, error)
+	AddTeamMembers(ctx context.Context, teamName string, members []domain.User, allowMoves bool) (domain.Team, error)
+	RemoveTeamMembers(ctx context.Context, teamName string, userIDs []string, reassign bool) (domain.Team, []domain.ReviewerReassignment, error)
+	RenameTeam(ctx context.Context, oldName, newName string) (domain.Team, error)
+	ArchiveTeam(ctx context.Context, teamName string, reassign bool) (domain.Team, []domain.ReviewerReassignment, error)
+	UnarchiveTeam(ctx context.Context, teamName string) (domain.Team, error)
+	DeleteTeam(ctx context.Context, teamName string) error
+	MoveUser(ctx context.Context, userID, teamName string, reassign bool) (domain.User, []domain.ReviewerReassignment, error)
+	JoinTeam(ctx context.Context, userID, teamName string) (domain.User, error)
+	ListTeamMoves(ctx context.Context, userID string) ([]domain.TeamMove, error)
 	SetUserActivity(ctx context.Context, userID string, isActive bool) (domain.User, error)
-	CreatePullRequest(ctx context.Context, id, name, authorID string) (domain.PullRequest, error)
+	SetUserTags(ctx context.Context, userID string, tags []string) (domain.User, error)
+	SetUserCapacity(ctx context.Context, userID string, maxOpenReviews int) (domain.User, error)
+	AddUnavailability(ctx context.Context, window domain.UnavailabilityWindow) (domain.UnavailabilityWindow, error)
+	ListUnavailability(ctx context.Context, userID string) ([]domain.UnavailabilityWindow, error)
+	DeleteUnavailability(ctx context.Context, userID string, windowID int64) error
+	DeactivateUser(ctx context.Context, userID string) (domain.User, []domain.ReviewerReassignment, error)
+	DeactivateTeamMembers(ctx context.Context, teamName string, userIDs []string, reassign bool) ([]domain.User, []domain.ReviewerReassignment, error)
+	CreatePullRequest(ctx context.Context, id, name, authorID string, opts domain.PullRequestOptions) (domain.PullRequest, error)
 	GetPullRequest(ctx context.Context, id string) (domain.PullRequest, error)
-	MergePullRequest(ctx context.Context, id string) (domain.PullRequest, error)
-	ReassignReviewer(ctx context.Context, prID, oldReviewerID string) (domain.PullRequest, string, error)
+	MergePullRequest(ctx context.Context, id string, opts domain.MergeOptions) (domain.PullRequest, error)
+	UpdatePullRequest(ctx context.Context, id string, update domain.PullRequestUpdate) (domain.PullRequest, []domain.ReviewerReassignment, error)
+	MarkPullRequestReady(ctx context.Context, id string) (domain.PullRequest, error)
+	ClosePullRequest(ctx context.Context, id string) (domain.PullRequest, error)
+	ReopenPullRequest(ctx context.Context, id string) (domain.PullRequest, []domain.ReviewerReassignment, error)
+	ListForcedMerges(ctx context.Context) ([]domain.ForcedMerge, error)
+	ReassignReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) (domain.PullRequest, string, error)
+	AddReviewer(ctx context.Context, prID, userID string) (domain.PullRequest, error)
+	RemoveReviewer(ctx context.Context, prID, userID string) (domain.PullRequest, error)
+	SwapReviewer(ctx context.Context, prID, oldUserID, newUserID string) (domain.PullRequest, error)
+	DeclineReview(ctx context.Context, prID, userID, reason string) (domain.PullRequest, string, error)
+	SubmitReview(ctx context.Context, prID, reviewerID string, state domain.ReviewState) (domain.PullRequest, error)
+	BackfillReviewers(ctx context.Context) ([]domain.ReviewerBackfill, error)
+	ListOverdueReviews(ctx context.Context, at time.Time) ([]domain.PendingReview, error)
+	EscalateOverdueReviews(ctx context.Context, at time.Time) ([]domain.SLAEscalation, error)
+	ListOwnershipRules(ctx context.Context) ([]domain.OwnershipRule, error)
+	CreateOwnershipRule(ctx context.Context, rule domain.OwnershipRule) (domain.OwnershipRule, error)
+	UpdateOwnershipRule(ctx context.Context, ruleID int64, update domain.OwnershipRuleUpdate) (domain.OwnershipRule, error)
+	DeleteOwnershipRule(ctx context.Context, ruleID int64) error
+	ReplaceOwnershipRules(ctx context.Context, rules []domain.OwnershipRule) ([]domain.OwnershipRule, error)
 	ListReviewerPullRequests(ctx context.Context, userID string) ([]domain.PullRequestShort, error)
-	GetReviewerStats(ctx context.Context) ([]repository.ReviewerStats, error)
-	GetPRStats(ctx context.Context) (repository.PRStats, error)
+	GetReviewerStats(ctx context.Context, teamName string, subtree bool) ([]repository.ReviewerStats, error)
+	GetPRStats(ctx context.Context, teamName string, subtree bool) (repository.PRStats, error)
 }
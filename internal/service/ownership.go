@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
+)
+
+func (s *service) ListOwnershipRules(ctx context.Context) ([]domain.OwnershipRule, error) {
+	rules, err := s.repo.ListOwnershipRules(ctx)
+	if err != nil {
+		log.Printf("[Service] ListOwnershipRules: error fetching rules: %v", err)
+		return nil, fmt.Errorf("failed to list ownership rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (s *service) CreateOwnershipRule(ctx context.Context, rule domain.OwnershipRule) (domain.OwnershipRule, error) {
+	if err := validateOwnershipRule(rule.Pattern, rule.Users, rule.Teams); err != nil {
+		log.Printf("[Service] CreateOwnershipRule: validation error - %v", err)
+		return domain.OwnershipRule{}, err
+	}
+	created, err := s.repo.CreateOwnershipRule(ctx, rule)
+	if err != nil {
+		log.Printf("[Service] CreateOwnershipRule: error creating rule %q: %v", rule.Pattern, err)
+		return domain.OwnershipRule{}, fmt.Errorf("failed to create ownership rule: %w", err)
+	}
+	log.Printf("[Service] CreateOwnershipRule: created rule %d for %q", created.ID, created.Pattern)
+	return created, nil
+}
+
+func (s *service) UpdateOwnershipRule(ctx context.Context, ruleID int64, update domain.OwnershipRuleUpdate) (domain.OwnershipRule, error) {
+	current, err := s.repo.GetOwnershipRule(ctx, ruleID)
+	if err != nil {
+		log.Printf("[Service] UpdateOwnershipRule: error fetching rule %d: %v", ruleID, err)
+		return domain.OwnershipRule{}, fmt.Errorf("failed to update ownership rule: %w", err)
+	}
+	if update.Pattern != nil {
+		current.Pattern = *update.Pattern
+	}
+	if update.Users != nil {
+		current.Users = *update.Users
+	}
+	if update.Teams != nil {
+		current.Teams = *update.Teams
+	}
+	if err := validateOwnershipRule(current.Pattern, current.Users, current.Teams); err != nil {
+		log.Printf("[Service] UpdateOwnershipRule: validation error - %v", err)
+		return domain.OwnershipRule{}, err
+	}
+	rule, err := s.repo.UpdateOwnershipRule(ctx, ruleID, update)
+	if err != nil {
+		log.Printf("[Service] UpdateOwnershipRule: error updating rule %d: %v", ruleID, err)
+		return domain.OwnershipRule{}, fmt.Errorf("failed to update ownership rule: %w", err)
+	}
+	log.Printf("[Service] UpdateOwnershipRule: updated rule %d", ruleID)
+	return rule, nil
+}
+
+func (s *service) DeleteOwnershipRule(ctx context.Context, ruleID int64) error {
+	if err := s.repo.DeleteOwnershipRule(ctx, ruleID); err != nil {
+		log.Printf("[Service] DeleteOwnershipRule: error deleting rule %d: %v", ruleID, err)
+		return fmt.Errorf("failed to delete ownership rule: %w", err)
+	}
+	log.Printf("[Service] DeleteOwnershipRule: deleted rule %d", ruleID)
+	return nil
+}
+
+func (s *service) ReplaceOwnershipRules(ctx context.Context, rules []domain.OwnershipRule) ([]domain.OwnershipRule, error) {
+	for _, rule := range rules {
+		if err := validateOwnershipRule(rule.Pattern, rule.Users, rule.Teams); err != nil {
+			log.Printf("[Service] ReplaceOwnershipRules: validation error - %v", err)
+			return nil, err
+		}
+	}
+	if err := s.repo.ReplaceOwnershipRules(ctx, rules); err != nil {
+		log.Printf("[Service] ReplaceOwnershipRules: error replacing rules: %v", err)
+		return nil, fmt.Errorf("failed to replace ownership rules: %w", err)
+	}
+	stored, err := s.repo.ListOwnershipRules(ctx)
+	if err != nil {
+		log.Printf("[Service] ReplaceOwnershipRules: error fetching rules: %v", err)
+		return nil, fmt.Errorf("failed to replace ownership rules: %w", err)
+	}
+	log.Printf("[Service] ReplaceOwnershipRules: stored %d rules", len(stored))
+	return stored, nil
+}
+
+func validateOwnershipRule(pattern string, users, teams []string) error {
+	if err := domain.ValidateOwnershipPattern(pattern); err != nil {
+		return err
+	}
+	if len(users) == 0 && len(teams) == 0 {
+		return domain.ErrNoOwners
+	}
+	return nil
+}
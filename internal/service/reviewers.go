@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
+)
+
+func (s *service) AddReviewer(ctx context.Context, prID, userID string) (domain.PullRequest, error) {
+	if strings.TrimSpace(prID) == "" {
+		return domain.PullRequest{}, errors.New("pull request ID is required")
+	}
+	if strings.TrimSpace(userID) == "" {
+		return domain.PullRequest{}, errors.New("user ID is required")
+	}
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		log.Printf("[Service] AddReviewer: error fetching PR %q: %v", prID, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to add reviewer: %w", err)
+	}
+	fallbackTeam, err := s.checkEligible(ctx, pr, userID)
+	if err != nil {
+		log.Printf("[Service] AddReviewer: user %q cannot review PR %q: %v", userID, prID, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to add reviewer: %w", err)
+	}
+	updated, err := s.repo.AddReviewers(ctx, prID, []string{userID}, map[string]string{userID: fallbackTeam}, 0)
+	if err != nil {
+		log.Printf("[Service] AddReviewer: error adding %q to PR %q: %v", userID, prID, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to add reviewer: %w", err)
+	}
+	log.Printf("[Service] AddReviewer: added %q to PR %q", userID, prID)
+	return updated, nil
+}
+
+// DeclineReview lets a reviewer step down. The replacement is picked by the
+// normal rules; without a candidate the reviewer is still removed and the
+// shortfall is reported.
+func (s *service) DeclineReview(ctx context.Context, prID, userID, reason string) (domain.PullRequest, string, error) {
+	if strings.TrimSpace(prID) == "" {
+		return domain.PullRequest{}, "", errors.New("pull request ID is required")
+	}
+	if strings.TrimSpace(userID) == "" {
+		return domain.PullRequest{}, "", errors.New("user ID is required")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return domain.PullRequest{}, "", errors.New("decline reason is required")
+	}
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		log.Printf("[Service] DeclineReview: error fetching PR %q: %v", prID, err)
+		return domain.PullRequest{}, "", fmt.Errorf("failed to decline review: %w", err)
+	}
+	if err := pr.CheckOpen(); err != nil {
+		log.Printf("[Service] DeclineReview: cannot decline on PR %q: %v", prID, err)
+		return domain.PullRequest{}, "", err
+	}
+	if _, ok := idSet(pr.AssignedReviewers)[userID]; !ok {
+		log.Printf("[Service] DeclineReview: user %q is not assigned to PR %q", userID, prID)
+		return domain.PullRequest{}, "", domain.ErrNotAssigned
+	}
+
+	selected, err := s.pickReplacement(ctx, pr, userID)
+	if err != nil {
+		log.Printf("[Service] DeclineReview: error selecting candidate for PR %q: %v", prID, err)
+		return domain.PullRequest{}, "", fmt.Errorf("failed to decline review: %w", err)
+	}
+	var replacement string
+	if len(selected.reviewers) > 0 {
+		replacement = selected.reviewers[0].ID
+	}
+
+	updated, err := s.repo.DeclineReview(ctx, domain.ReviewDecline{
+		PullRequestID: prID,
+		UserID:        userID,
+		ReplacedBy:    replacement,
+		Reason:        reason,
+	}, selected.fallback[replacement])
+	if err != nil {
+		log.Printf("[Service] DeclineReview: error declining %q on PR %q: %v", userID, prID, err)
+		return domain.PullRequest{}, "", fmt.Errorf("failed to decline review: %w", err)
+	}
+	s.recordAssigned(selected)
+
+	updated.Shortfall = selected.shortfall(1)
+	if updated.Shortfall != nil {
+		log.Printf("[Service] DeclineReview: %q declined PR %q without replacement: %s", userID, prID, updated.Shortfall.Message())
+		return updated, "", nil
+	}
+	log.Printf("[Service] DeclineReview: %q declined PR %q, replaced by %q", userID, prID, replacement)
+	return updated, replacement, nil
+}
+
+func (s *service) RemoveReviewer(ctx context.Context, prID, userID string) (domain.PullRequest, error) {
+	if strings.TrimSpace(prID) == "" {
+		return domain.PullRequest{}, errors.New("pull request ID is required")
+	}
+	if strings.TrimSpace(userID) == "" {
+		return domain.PullRequest{}, errors.New("user ID is required")
+	}
+	updated, err := s.repo.RemoveReviewer(ctx, prID, userID)
+	if err != nil {
+		log.Printf("[Service] RemoveReviewer: error removing %q from PR %q: %v", userID, prID, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to remove reviewer: %w", err)
+	}
+	log.Printf("[Service] RemoveReviewer: removed %q from PR %q", userID, prID)
+	return updated, nil
+}
+
+func (s *service) SwapReviewer(ctx context.Context, prID, oldUserID, newUserID string) (domain.PullRequest, error) {
+	if strings.TrimSpace(prID) == "" {
+		return domain.PullRequest{}, errors.New("pull request ID is required")
+	}
+	if strings.TrimSpace(oldUserID) == "" || strings.TrimSpace(newUserID) == "" {
+		return domain.PullRequest{}, errors.New("old and new user IDs are required")
+	}
+	pr, err := s.repo.GetPullRequest(ctx, prID)
+	if err != nil {
+		log.Printf("[Service] SwapReviewer: error fetching PR %q: %v", prID, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to swap reviewer: %w", err)
+	}
+	if _, ok := idSet(pr.AssignedReviewers)[oldUserID]; !ok {
+		log.Printf("[Service] SwapReviewer: user %q is not assigned to PR %q", oldUserID, prID)
+		return domain.PullRequest{}, domain.ErrNotAssigned
+	}
+	fallbackTeam, err := s.checkEligible(ctx, pr, newUserID)
+	if err != nil {
+		log.Printf("[Service] SwapReviewer: user %q cannot review PR %q: %v", newUserID, prID, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to swap reviewer: %w", err)
+	}
+	updated, err := s.repo.ReassignReviewer(ctx, prID, oldUserID, newUserID, fallbackTeam)
+	if err != nil {
+		log.Printf("[Service] SwapReviewer: error replacing %q with %q in PR %q: %v", oldUserID, newUserID, prID, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to swap reviewer: %w", err)
+	}
+	log.Printf("[Service] SwapReviewer: replaced %q with %q in PR %q", oldUserID, newUserID, prID)
+	return updated, nil
+}
+
+// checkEligible applies the same rules to a hand-picked reviewer as automatic
+// selection does: PR and user state, the pools selection draws from,
+// unavailability windows and review limit. It returns the fallback team the
+// reviewer comes from, empty for the author's team and code owners.
+func (s *service) checkEligible(ctx context.Context, pr domain.PullRequest, userID string) (string, error) {
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if err := pr.CanAssign(user); err != nil {
+		return "", err
+	}
+	fallbackTeam, err := s.allowedTeam(ctx, pr, user)
+	if err != nil {
+		return "", err
+	}
+
+	available, err := s.repo.ListAvailableUserIDs(ctx, []string{userID}, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if len(available) == 0 {
+		return "", domain.ErrUserUnavailable
+	}
+
+	load, err := s.repo.GetOpenReviewLoad(ctx, []string{userID})
+	if err != nil {
+		return "", err
+	}
+	capacity, err := s.repo.GetReviewCapacity(ctx, []string{userID})
+	if err != nil {
+		return "", err
+	}
+	if limit, ok := capacity[userID]; ok && domain.ExceedsCapacity(load[userID], pr.LoadUnits(), limit) {
+		return "", domain.ErrAtCapacity
+	}
+	return fallbackTeam, nil
+}
+
+func (s *service) allowedTeam(ctx context.Context, pr domain.PullRequest, user domain.User) (string, error) {
+	_, team, err := s.authorTeam(ctx, pr.AuthorID, pr.TeamName)
+	if err != nil {
+		return "", err
+	}
+	if user.InTeam(team.Name) {
+		return "", nil
+	}
+	for _, name := range team.FallbackTeams {
+		if user.InTeam(name) {
+			return name, nil
+		}
+	}
+	ancestors, err := s.ancestorTeams(ctx, team)
+	if err != nil {
+		return "", err
+	}
+	for _, parent := range ancestors {
+		if user.InTeam(parent.Name) {
+			return parent.Name, nil
+		}
+	}
+	owners, err := s.codeOwners(ctx, pr.ChangedFiles, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, owner := range owners {
+		if owner.ID == user.ID {
+			return "", nil
+		}
+	}
+	return "", domain.ErrTeamNotAllowed
+}
@@ -0,0 +1,431 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
+)
+
+type selectionRequest struct {
+	author   domain.User
+	team     domain.Team
+	owners   []domain.User
+	tags     []string
+	covered  []string
+	excluded map[string]struct{}
+	pending  map[string]int
+	count    int
+	units    int
+	urgent   bool
+}
+
+type selection struct {
+	reviewers  []domain.User
+	fallback   map[string]string
+	rankedBy   map[string]string
+	atCapacity []string
+}
+
+func (sel selection) ids() []string {
+	return reviewerIDs(sel.reviewers)
+}
+
+// shortfall explains why fewer than count reviewers were selected, nil when
+// the selection is complete.
+func (sel selection) shortfall(count int) *domain.ReviewerShortfall {
+	missing := count - len(sel.reviewers)
+	if missing <= 0 {
+		return nil
+	}
+	if len(sel.atCapacity) > 0 {
+		return &domain.ReviewerShortfall{Missing: missing, Reason: domain.ShortfallCapacity, AtCapacity: sel.atCapacity}
+	}
+	return &domain.ReviewerShortfall{Missing: missing, Reason: domain.ShortfallNoCandidates}
+}
+
+func (sel *selection) add(users []domain.User, strategyTeam, fallbackTeam string) {
+	if sel.rankedBy == nil {
+		sel.rankedBy = make(map[string]string)
+	}
+	sel.reviewers = append(sel.reviewers, users...)
+	for _, u := range users {
+		sel.rankedBy[u.ID] = strategyTeam
+		if fallbackTeam == "" {
+			continue
+		}
+		if sel.fallback == nil {
+			sel.fallback = make(map[string]string)
+		}
+		sel.fallback[u.ID] = fallbackTeam
+	}
+}
+
+// pickReviewers takes one code owner first when owners are required, then
+// ranks the home team and only walks the team's fallback pools, in order,
+// while the requested count is still not covered. Urgent PRs skip the walk:
+// the home team and all fallback pools are ranked together by load. When that
+// is still not enough, the parent teams are tried one level up at a time.
+func (s *service) pickReviewers(ctx context.Context, req selectionRequest) (selection, error) {
+	var sel selection
+	if req.count <= 0 {
+		return sel, nil
+	}
+
+	excluded := make(map[string]struct{}, len(req.excluded))
+	for id := range req.excluded {
+		excluded[id] = struct{}{}
+	}
+	covered := idSet(req.covered)
+	need := func() int {
+		for _, u := range sel.reviewers {
+			excluded[u.ID] = struct{}{}
+			for _, tag := range u.Tags {
+				covered[tag] = struct{}{}
+			}
+		}
+		return req.count - len(sel.reviewers)
+	}
+	addPool := func(pool domain.Team, n int) error {
+		more, blocked, err := s.rankCandidates(ctx, req, pool.Name, poolMembers(pool), excluded, covered, n)
+		if err != nil {
+			return err
+		}
+		sel.add(more, pool.Name, pool.Name)
+		sel.atCapacity = append(sel.atCapacity, blocked...)
+		return nil
+	}
+
+	if len(req.owners) > 0 {
+		owners, blocked, err := s.rankCandidates(ctx, req, req.team.Name, req.owners, excluded, covered, 1)
+		if err != nil {
+			return sel, err
+		}
+		sel.add(owners, req.team.Name, "")
+		sel.atCapacity = append(sel.atCapacity, blocked...)
+	}
+
+	if req.urgent {
+		if n := need(); n > 0 {
+			pool, fallbackOf, err := s.urgentPool(ctx, req.team)
+			if err != nil {
+				return sel, err
+			}
+			picked, blocked, err := s.rankCandidates(ctx, req, req.team.Name, pool, excluded, covered, n)
+			if err != nil {
+				return sel, err
+			}
+			for _, u := range picked {
+				if name, ok := fallbackOf[u.ID]; ok {
+					sel.add([]domain.User{u}, name, name)
+				} else {
+					sel.add([]domain.User{u}, req.team.Name, "")
+				}
+			}
+			sel.atCapacity = append(sel.atCapacity, blocked...)
+		}
+	} else {
+		if n := need(); n > 0 {
+			home, blocked, err := s.rankCandidates(ctx, req, req.team.Name, poolMembers(req.team), excluded, covered, n)
+			if err != nil {
+				return sel, err
+			}
+			sel.add(home, req.team.Name, "")
+			sel.atCapacity = append(sel.atCapacity, blocked...)
+		}
+
+		for _, name := range req.team.FallbackTeams {
+			n := need()
+			if n <= 0 {
+				break
+			}
+			pool, err := s.repo.GetTeam(ctx, name)
+			if errors.Is(err, domain.ErrTeamNotFound) {
+				continue
+			}
+			if err != nil {
+				return sel, err
+			}
+			if err := addPool(pool, n); err != nil {
+				return sel, err
+			}
+		}
+	}
+
+	if need() > 0 && req.team.ParentTeam != "" {
+		ancestors, err := s.ancestorTeams(ctx, req.team)
+		if err != nil {
+			return sel, err
+		}
+		for _, pool := range ancestors {
+			n := need()
+			if n <= 0 {
+				break
+			}
+			if err := addPool(pool, n); err != nil {
+				return sel, err
+			}
+		}
+	}
+
+	sel.atCapacity = uniqueIDs(sel.atCapacity)
+	return sel, nil
+}
+
+// urgentPool merges the home team with its fallback teams. fallbackOf maps
+// the members that come from a fallback team to that team.
+func (s *service) urgentPool(ctx context.Context, team domain.Team) ([]domain.User, map[string]string, error) {
+	pool := append([]domain.User(nil), poolMembers(team)...)
+	seen := make(map[string]struct{}, len(pool))
+	for _, u := range pool {
+		seen[u.ID] = struct{}{}
+	}
+	fallbackOf := make(map[string]string)
+	for _, name := range team.FallbackTeams {
+		fallback, err := s.repo.GetTeam(ctx, name)
+		if errors.Is(err, domain.ErrTeamNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, u := range poolMembers(fallback) {
+			if _, dup := seen[u.ID]; dup {
+				continue
+			}
+			seen[u.ID] = struct{}{}
+			fallbackOf[u.ID] = fallback.Name
+			pool = append(pool, u)
+		}
+	}
+	return pool, fallbackOf, nil
+}
+
+// poolMembers returns the members a team contributes as candidates, none
+// for an archived team; its members can still be picked through their other
+// teams.
+func poolMembers(team domain.Team) []domain.User {
+	if team.Archived() {
+		return nil
+	}
+	return team.Members
+}
+
+// rankCandidates returns up to count ranked candidates and, separately, the
+// candidates that were skipped only because they reached their review limit.
+func (s *service) rankCandidates(ctx context.Context, req selectionRequest, strategyTeam string, candidates []domain.User, excluded, covered map[string]struct{}, count int) ([]domain.User, []string, error) {
+	var ids []string
+	for _, member := range candidates {
+		if _, skip := excluded[member.ID]; !skip && member.ID != req.author.ID {
+			ids = append(ids, member.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+
+	available, err := s.repo.ListAvailableUserIDs(ctx, ids, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(available) == 0 {
+		return nil, nil, nil
+	}
+
+	load, err := s.repo.GetOpenReviewLoad(ctx, available)
+	if err != nil {
+		return nil, nil, err
+	}
+	for id, extra := range req.pending {
+		load[id] += extra
+	}
+	capacity, err := s.repo.GetReviewCapacity(ctx, available)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	availableSet := idSet(available)
+	roster := make([]domain.User, 0, len(available))
+	var blocked []string
+	for _, member := range candidates {
+		if _, ok := availableSet[member.ID]; !ok {
+			continue
+		}
+		if limit, ok := capacity[member.ID]; ok && domain.ExceedsCapacity(load[member.ID], req.units, limit) {
+			blocked = append(blocked, member.ID)
+			continue
+		}
+		roster = append(roster, member)
+	}
+	if len(roster) == 0 {
+		return nil, blocked, nil
+	}
+
+	strategy := s.strategies.For(strategyTeam)
+	if req.urgent {
+		strategy = leastLoadedStrategy{}
+	}
+	ranked := strategy.Rank(req.author, roster, load)
+	ranked = preferTagged(ranked, req.tags, covered)
+	if len(ranked) > count {
+		ranked = ranked[:count]
+	}
+	return ranked, blocked, nil
+}
+
+// preferTagged moves candidates that cover still missing required tags to
+// the front, greedily taking the one that adds the most tags. Candidates that
+// add nothing keep the strategy order.
+func preferTagged(ranked []domain.User, tags []string, covered map[string]struct{}) []domain.User {
+	missing := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		if _, ok := covered[tag]; !ok {
+			missing[tag] = struct{}{}
+		}
+	}
+
+	rest := append([]domain.User(nil), ranked...)
+	out := make([]domain.User, 0, len(ranked))
+	for len(missing) > 0 && len(rest) > 0 {
+		best, bestCount := -1, 0
+		for i, u := range rest {
+			n := 0
+			for _, tag := range u.Tags {
+				if _, ok := missing[tag]; ok {
+					n++
+				}
+			}
+			if n > bestCount {
+				best, bestCount = i, n
+			}
+		}
+		if best < 0 {
+			break
+		}
+		for _, tag := range rest[best].Tags {
+			delete(missing, tag)
+		}
+		out = append(out, rest[best])
+		rest = append(rest[:best], rest[best+1:]...)
+	}
+	return append(out, rest...)
+}
+
+// tagsOf returns the tags already brought to a PR by the given reviewers.
+func (s *service) tagsOf(ctx context.Context, required, reviewerIDs []string) ([]string, error) {
+	if len(required) == 0 || len(reviewerIDs) == 0 {
+		return nil, nil
+	}
+	users, err := s.repo.ListUsersByIDsOrTeams(ctx, reviewerIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, u := range users {
+		tags = append(tags, u.Tags...)
+	}
+	return tags, nil
+}
+
+// codeOwners resolves the owners of the touched paths. It returns nil when
+// there are no owners or one of the reviewers that stay on the PR already
+// owns the change, so no owner pick is required.
+func (s *service) codeOwners(ctx context.Context, changedFiles []string, staying []string) ([]domain.User, error) {
+	if len(changedFiles) == 0 {
+		return nil, nil
+	}
+
+	rules, err := s.repo.ListOwnershipRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var userIDs, teamNames []string
+	for _, path := range changedFiles {
+		rule, ok := domain.MatchingRule(rules, path)
+		if !ok {
+			continue
+		}
+		userIDs = append(userIDs, rule.Users...)
+		teamNames = append(teamNames, rule.Teams...)
+	}
+	if len(userIDs) == 0 && len(teamNames) == 0 {
+		return nil, nil
+	}
+
+	owners, err := s.repo.ListCodeOwners(ctx, userIDs, teamNames)
+	if err != nil {
+		return nil, err
+	}
+
+	stay := idSet(staying)
+	for _, owner := range owners {
+		if _, ok := stay[owner.ID]; ok {
+			return nil, nil
+		}
+	}
+	return owners, nil
+}
+
+func (s *service) recordAssigned(sel selection) {
+	for _, u := range sel.reviewers {
+		s.strategies.For(sel.rankedBy[u.ID]).Assigned([]string{u.ID})
+	}
+}
+
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	out := ids[:0]
+	for _, id := range ids {
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+func idSet(ids []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
+
+// unfitReviewers returns the assigned reviewers of pr that would not be picked
+// for it now: inactive, unavailable or without room for the review under
+// their limit. The PR itself must not be counted in their load.
+func (s *service) unfitReviewers(ctx context.Context, pr domain.PullRequest) (map[string]struct{}, error) {
+	unfit := make(map[string]struct{})
+	if len(pr.AssignedReviewers) == 0 {
+		return unfit, nil
+	}
+
+	available, err := s.repo.ListAvailableUserIDs(ctx, pr.AssignedReviewers, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	load, err := s.repo.GetOpenReviewLoad(ctx, pr.AssignedReviewers)
+	if err != nil {
+		return nil, err
+	}
+	capacity, err := s.repo.GetReviewCapacity(ctx, pr.AssignedReviewers)
+	if err != nil {
+		return nil, err
+	}
+
+	availableSet := idSet(available)
+	for _, id := range pr.AssignedReviewers {
+		if _, ok := availableSet[id]; !ok {
+			unfit[id] = struct{}{}
+			continue
+		}
+		if limit, ok := capacity[id]; ok && domain.ExceedsCapacity(load[id], pr.LoadUnits(), limit) {
+			unfit[id] = struct{}{}
+		}
+	}
+	return unfit, nil
+}
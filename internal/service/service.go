@@ -12,14 +12,17 @@ import (
 )
 
 type service struct {
-	repo *repository.Repository
+	repo       *repository.Repository
+	strategies *Strategies
+	workday    domain.Workday
 }
 
-func New(repo *repository.Repository) Service {
-	return &service{repo: repo}
+// New creates the service. workday sets the working hours review SLAs count.
+func New(repo *repository.Repository, strategies *Strategies, workday domain.Workday) Service {
+	return &service{repo: repo, strategies: strategies, workday: workday}
 }
 
-func (s *service) CreateTeam(ctx context.Context, team domain.Team) (domain.Team, error) {
+func (s *service) CreateTeam(ctx context.Context, team domain.Team, allowMoves bool) (domain.Team, error) {
 	if len(team.Members) == 0 {
 		log.Printf("[Service] CreateTeam: validation error - team %q has no members\"", team.Name)
 		return domain.Team{}, errors.New("team must have at least one member")
@@ -28,7 +31,50 @@ func (s *service) CreateTeam(ctx context.Context, team domain.Team) (domain.Team
 		log.Printf("[Service] CreateTeam: validation error - team name is required")
 		return domain.Team{}, errors.New("team name is required")
 	}
-	created, err := s.repo.CreateTeam(ctx, team)
+	if team.RequiredReviewers == 0 {
+		team.RequiredReviewers = domain.DefaultRequiredReviewers
+	}
+	if team.RequiredReviewers < 0 {
+		log.Printf("[Service] CreateTeam: validation error - invalid required reviewers %d", team.RequiredReviewers)
+		return domain.Team{}, errors.New("required reviewers must be positive")
+	}
+	if team.MaxOpenReviews < 0 {
+		log.Printf("[Service] CreateTeam: validation error - invalid review limit %d", team.MaxOpenReviews)
+		return domain.Team{}, errors.New("max open reviews must not be negative")
+	}
+	if team.RequiredApprovals < 0 {
+		log.Printf("[Service] CreateTeam: validation error - invalid required approvals %d", team.RequiredApprovals)
+		return domain.Team{}, errors.New("required approvals must not be negative")
+	}
+	if team.ReviewSLAHours < 0 {
+		log.Printf("[Service] CreateTeam: validation error - invalid review SLA %d", team.ReviewSLAHours)
+		return domain.Team{}, errors.New("review SLA must not be negative")
+	}
+	if team.SLAAction == "" {
+		team.SLAAction = domain.SLAActionReassign
+	}
+	if !team.SLAAction.Valid() {
+		log.Printf("[Service] CreateTeam: validation error - unknown SLA action %q", team.SLAAction)
+		return domain.Team{}, fmt.Errorf("unknown SLA action %q", team.SLAAction)
+	}
+	if err := validateFallbackTeams(team.Name, team.FallbackTeams); err != nil {
+		log.Printf("[Service] CreateTeam: validation error - %v", err)
+		return domain.Team{}, err
+	}
+	if team.ParentTeam == team.Name {
+		log.Printf("[Service] CreateTeam: validation error - team %q is its own parent", team.Name)
+		return domain.Team{}, domain.ErrTeamCycle
+	}
+	for i := range team.Members {
+		if team.Members[i].MaxOpenReviews < 0 {
+			log.Printf("[Service] CreateTeam: validation error - invalid review limit for %q", team.Members[i].ID)
+			return domain.Team{}, errors.New("max open reviews must not be negative")
+		}
+		if team.Members[i].Tags != nil {
+			team.Members[i].Tags = domain.NormalizeTags(team.Members[i].Tags)
+		}
+	}
+	created, err := s.repo.CreateTeam(ctx, team, allowMoves)
 	if err != nil {
 		log.Printf("[Service] CreateTeam: failed to create team %q: %v", team.Name, err)
 		return domain.Team{}, fmt.Errorf("failed to create team: %w", err)
@@ -51,6 +97,67 @@ func (s *service) GetTeam(ctx context.Context, teamName string) (domain.Team, er
 	return team, nil
 }
 
+func (s *service) UpdateTeam(ctx context.Context, teamName string, update domain.TeamUpdate) (domain.Team, error) {
+	if strings.TrimSpace(teamName) == "" {
+		log.Printf("[Service] UpdateTeam: validation error - team name is required")
+		return domain.Team{}, errors.New("team name is required")
+	}
+	if update.RequiredReviewers != nil && *update.RequiredReviewers <= 0 {
+		log.Printf("[Service] UpdateTeam: validation error - invalid required reviewers %d", *update.RequiredReviewers)
+		return domain.Team{}, errors.New("required reviewers must be positive")
+	}
+	if update.MaxOpenReviews != nil && *update.MaxOpenReviews < 0 {
+		log.Printf("[Service] UpdateTeam: validation error - invalid review limit %d", *update.MaxOpenReviews)
+		return domain.Team{}, errors.New("max open reviews must not be negative")
+	}
+	if update.RequiredApprovals != nil && *update.RequiredApprovals < 0 {
+		log.Printf("[Service] UpdateTeam: validation error - invalid required approvals %d", *update.RequiredApprovals)
+		return domain.Team{}, errors.New("required approvals must not be negative")
+	}
+	if update.ReviewSLAHours != nil && *update.ReviewSLAHours < 0 {
+		log.Printf("[Service] UpdateTeam: validation error - invalid review SLA %d", *update.ReviewSLAHours)
+		return domain.Team{}, errors.New("review SLA must not be negative")
+	}
+	if update.SLAAction != nil && !update.SLAAction.Valid() {
+		log.Printf("[Service] UpdateTeam: validation error - unknown SLA action %q", *update.SLAAction)
+		return domain.Team{}, fmt.Errorf("unknown SLA action %q", *update.SLAAction)
+	}
+	if update.FallbackTeams != nil {
+		if err := validateFallbackTeams(teamName, *update.FallbackTeams); err != nil {
+			log.Printf("[Service] UpdateTeam: validation error - %v", err)
+			return domain.Team{}, err
+		}
+	}
+	if update.ParentTeam != nil && *update.ParentTeam == teamName {
+		log.Printf("[Service] UpdateTeam: validation error - team %q is its own parent", teamName)
+		return domain.Team{}, domain.ErrTeamCycle
+	}
+	team, err := s.repo.UpdateTeam(ctx, teamName, update)
+	if err != nil {
+		log.Printf("[Service] UpdateTeam: failed to update team %q: %v", teamName, err)
+		return domain.Team{}, fmt.Errorf("failed to update team: %w", err)
+	}
+	log.Printf("[Service] UpdateTeam: successfully updated team %q", team.Name)
+	return team, nil
+}
+
+func validateFallbackTeams(teamName string, fallbackTeams []string) error {
+	seen := make(map[string]struct{}, len(fallbackTeams))
+	for _, name := range fallbackTeams {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%w: fallback team name is required", domain.ErrInvalidFallbackTeams)
+		}
+		if name == teamName {
+			return fmt.Errorf("%w: team cannot be its own fallback", domain.ErrInvalidFallbackTeams)
+		}
+		if _, dup := seen[name]; dup {
+			return fmt.Errorf("%w: fallback team %q is listed twice", domain.ErrInvalidFallbackTeams, name)
+		}
+		seen[name] = struct{}{}
+	}
+	return nil
+}
+
 func (s *service) SetUserActivity(ctx context.Context, userID string, isActive bool) (domain.User, error) {
 	if strings.TrimSpace(userID) == "" {
 		log.Printf("[Service] SetUserActivity: validation error - user ID is required")
@@ -65,7 +172,276 @@ func (s *service) SetUserActivity(ctx context.Context, userID string, isActive b
 	return user, nil
 }
 
-func (s *service) CreatePullRequest(ctx context.Context, id, name, authorID string) (domain.PullRequest, error) {
+func (s *service) SetUserTags(ctx context.Context, userID string, tags []string) (domain.User, error) {
+	if strings.TrimSpace(userID) == "" {
+		log.Printf("[Service] SetUserTags: validation error - user ID is required")
+		return domain.User{}, errors.New("user ID is required")
+	}
+	user, err := s.repo.SetUserTags(ctx, userID, domain.NormalizeTags(tags))
+	if err != nil {
+		log.Printf("[Service] SetUserTags: failed to set user %q tags: %v", userID, err)
+		return domain.User{}, fmt.Errorf("failed to set user tags: %w", err)
+	}
+	log.Printf("[Service] SetUserTags: successfully set %d tags for user %q", len(user.Tags), user.Username)
+	return user, nil
+}
+
+func (s *service) SetUserCapacity(ctx context.Context, userID string, maxOpenReviews int) (domain.User, error) {
+	if strings.TrimSpace(userID) == "" {
+		log.Printf("[Service] SetUserCapacity: validation error - user ID is required")
+		return domain.User{}, errors.New("user ID is required")
+	}
+	if maxOpenReviews < 0 {
+		log.Printf("[Service] SetUserCapacity: validation error - invalid review limit %d", maxOpenReviews)
+		return domain.User{}, errors.New("max open reviews must not be negative")
+	}
+	user, err := s.repo.SetUserCapacity(ctx, userID, maxOpenReviews)
+	if err != nil {
+		log.Printf("[Service] SetUserCapacity: failed to set user %q review limit: %v", userID, err)
+		return domain.User{}, fmt.Errorf("failed to set user capacity: %w", err)
+	}
+	log.Printf("[Service] SetUserCapacity: successfully set review limit of user %q to %d", user.Username, maxOpenReviews)
+	return user, nil
+}
+
+func (s *service) DeactivateUser(ctx context.Context, userID string) (domain.User, []domain.ReviewerReassignment, error) {
+	if strings.TrimSpace(userID) == "" {
+		log.Printf("[Service] DeactivateUser: validation error - user ID is required")
+		return domain.User{}, nil, errors.New("user ID is required")
+	}
+	users, moves, err := s.deactivate(ctx, []string{userID}, true)
+	if err != nil {
+		log.Printf("[Service] DeactivateUser: failed to deactivate user %q: %v", userID, err)
+		return domain.User{}, nil, fmt.Errorf("failed to deactivate user: %w", err)
+	}
+	log.Printf("[Service] DeactivateUser: deactivated user %q, %d open reviews affected", userID, len(moves))
+	return users[0], moves, nil
+}
+
+func (s *service) DeactivateTeamMembers(ctx context.Context, teamName string, userIDs []string, reassign bool) ([]domain.User, []domain.ReviewerReassignment, error) {
+	if strings.TrimSpace(teamName) == "" {
+		log.Printf("[Service] DeactivateTeamMembers: validation error - team name is required")
+		return nil, nil, errors.New("team name is required")
+	}
+	team, err := s.repo.GetTeam(ctx, teamName)
+	if err != nil {
+		log.Printf("[Service] DeactivateTeamMembers: failed to get team %q: %v", teamName, err)
+		return nil, nil, fmt.Errorf("failed to deactivate team members: %w", err)
+	}
+
+	members := make(map[string]struct{}, len(team.Members))
+	for _, member := range team.Members {
+		members[member.ID] = struct{}{}
+	}
+	if len(userIDs) == 0 {
+		for _, member := range team.Members {
+			userIDs = append(userIDs, member.ID)
+		}
+	}
+	targets := make([]string, 0, len(userIDs))
+	for id := range idSet(userIDs) {
+		if _, ok := members[id]; !ok {
+			log.Printf("[Service] DeactivateTeamMembers: user %q is not a member of team %q", id, teamName)
+			return nil, nil, fmt.Errorf("user %q is not a member of team %q: %w", id, teamName, domain.ErrUserNotFound)
+		}
+		targets = append(targets, id)
+	}
+
+	users, moves, err := s.deactivate(ctx, targets, reassign)
+	if err != nil {
+		log.Printf("[Service] DeactivateTeamMembers: failed to deactivate members of team %q: %v", teamName, err)
+		return nil, nil, fmt.Errorf("failed to deactivate team members: %w", err)
+	}
+	log.Printf("[Service] DeactivateTeamMembers: deactivated %d members of team %q, %d open reviews affected", len(users), teamName, len(moves))
+	return users, moves, nil
+}
+
+func (s *service) deactivate(ctx context.Context, userIDs []string, reassign bool) ([]domain.User, []domain.ReviewerReassignment, error) {
+	var moves []domain.ReviewerReassignment
+	var picked selection
+	if reassign {
+		var err error
+		moves, picked, err = s.planReassignments(ctx, leavingAll(userIDs))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	users, err := s.repo.SetUsersActivity(ctx, userIDs, false, moves)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.recordAssigned(picked)
+	return users, moves, nil
+}
+
+// planReassignments picks replacements for the OPEN reviews of the leaving
+// users. leaving maps each user to the team whose PRs they leave, or to an
+// empty name when they leave all their OPEN reviews.
+func (s *service) planReassignments(ctx context.Context, leaving map[string]string) ([]domain.ReviewerReassignment, selection, error) {
+	var picked selection
+	leavingIDs := make([]string, 0, len(leaving))
+	for id := range leaving {
+		leavingIDs = append(leavingIDs, id)
+	}
+	prs, err := s.repo.ListOpenReviews(ctx, leavingIDs)
+	if err != nil {
+		return nil, picked, err
+	}
+
+	teams := make(map[string]domain.Team)
+	pending := make(map[string]int)
+
+	var moves []domain.ReviewerReassignment
+	for _, pr := range prs {
+		prLeaving := make(map[string]struct{})
+		for id, teamName := range leaving {
+			if teamName == "" || teamName == pr.TeamName {
+				prLeaving[id] = struct{}{}
+			}
+		}
+		if !hasAny(pr.AssignedReviewers, prLeaving) {
+			continue
+		}
+		author, err := s.repo.GetUser(ctx, pr.AuthorID)
+		if err != nil {
+			return nil, picked, err
+		}
+		team, ok := teams[pr.TeamName]
+		if !ok {
+			team, err = s.repo.GetTeam(ctx, pr.TeamName)
+			if err != nil {
+				return nil, picked, err
+			}
+			teams[team.Name] = team
+		}
+
+		prMoves, err := s.planPullRequestMoves(ctx, pr, author, team, prLeaving, pending, &picked)
+		if err != nil {
+			return nil, picked, err
+		}
+		moves = append(moves, prMoves...)
+	}
+
+	return moves, picked, nil
+}
+
+// leavingAll marks the users as leaving all their OPEN reviews.
+func leavingAll(userIDs []string) map[string]string {
+	leaving := make(map[string]string, len(userIDs))
+	for _, id := range userIDs {
+		leaving[id] = ""
+	}
+	return leaving
+}
+
+// leavingTeam marks the members as leaving the OPEN reviews of teamName, or
+// all their OPEN reviews when it is their only team.
+func leavingTeam(members []domain.User, teamName string) map[string]string {
+	leaving := make(map[string]string, len(members))
+	for _, u := range members {
+		leaving[u.ID] = ""
+		for _, name := range u.Teams {
+			if name != teamName {
+				leaving[u.ID] = teamName
+				break
+			}
+		}
+	}
+	return leaving
+}
+
+func hasAny(ids []string, set map[string]struct{}) bool {
+	for _, id := range ids {
+		if _, ok := set[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// planPullRequestMoves picks a replacement for every leaving reviewer of pr.
+// pending and picked are shared between PRs of one batch so the load of
+// replacements chosen earlier is taken into account.
+func (s *service) planPullRequestMoves(ctx context.Context, pr domain.PullRequest, author domain.User, team domain.Team, leaving map[string]struct{}, pending map[string]int, picked *selection) ([]domain.ReviewerReassignment, error) {
+	excluded := idSet(pr.AssignedReviewers)
+	var staying []string
+	for _, id := range pr.AssignedReviewers {
+		if _, ok := leaving[id]; !ok {
+			staying = append(staying, id)
+		}
+	}
+	for id := range leaving {
+		excluded[id] = struct{}{}
+	}
+
+	var moves []domain.ReviewerReassignment
+	for _, reviewerID := range pr.AssignedReviewers {
+		if _, ok := leaving[reviewerID]; !ok {
+			continue
+		}
+		move := domain.ReviewerReassignment{PullRequestID: pr.ID, OldReviewerID: reviewerID}
+
+		owners, err := s.codeOwners(ctx, pr.ChangedFiles, staying)
+		if err != nil {
+			return nil, err
+		}
+		covered, err := s.tagsOf(ctx, pr.RequiredTags, staying)
+		if err != nil {
+			return nil, err
+		}
+		sel, err := s.pickReviewers(ctx, selectionRequest{
+			author:   author,
+			team:     team,
+			owners:   owners,
+			tags:     pr.RequiredTags,
+			covered:  covered,
+			excluded: excluded,
+			pending:  pending,
+			count:    1,
+			units:    pr.LoadUnits(),
+			urgent:   pr.Urgent(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(sel.reviewers) == 0 {
+			move.Reason = domain.ErrNoCandidate.Error()
+			if len(sel.atCapacity) > 0 {
+				move.Reason = domain.ErrAtCapacity.Error()
+			}
+		} else {
+			replacement := sel.reviewers[0]
+			move.NewReviewerID = replacement.ID
+			move.FallbackTeam = sel.fallback[replacement.ID]
+			excluded[replacement.ID] = struct{}{}
+			pending[replacement.ID] += pr.LoadUnits()
+			staying = append(staying, replacement.ID)
+			picked.add([]domain.User{replacement}, sel.rankedBy[replacement.ID], move.FallbackTeam)
+		}
+		moves = append(moves, move)
+	}
+
+	return moves, nil
+}
+
+// authorTeam loads the author and the team a PR is created for, the
+// author's primary team when teamName is empty.
+func (s *service) authorTeam(ctx context.Context, authorID, teamName string) (domain.User, domain.Team, error) {
+	author, err := s.repo.GetUser(ctx, authorID)
+	if err != nil {
+		return domain.User{}, domain.Team{}, err
+	}
+	if teamName == "" {
+		teamName = author.TeamName
+	}
+	team, err := s.repo.GetTeam(ctx, teamName)
+	if err != nil {
+		return domain.User{}, domain.Team{}, err
+	}
+	return author, team, nil
+}
+
+func (s *service) CreatePullRequest(ctx context.Context, id, name, authorID string, opts domain.PullRequestOptions) (domain.PullRequest, error) {
 	if strings.TrimSpace(id) == "" {
 		return domain.PullRequest{}, errors.New("pull request ID is required")
 	}
@@ -75,16 +451,189 @@ func (s *service) CreatePullRequest(ctx context.Context, id, name, authorID stri
 	if strings.TrimSpace(authorID) == "" {
 		return domain.PullRequest{}, errors.New("author ID is required")
 	}
-	pr, err := s.repo.CreatePullRequest(ctx, id, name, authorID)
+	if opts.SizeLines < 0 {
+		return domain.PullRequest{}, errors.New("size must not be negative")
+	}
+	author, team, err := s.authorTeam(ctx, authorID, opts.TeamName)
+	if err != nil {
+		log.Printf("[Service] CreatePullRequest: error fetching author %q and team: %v", authorID, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	if !author.InTeam(team.Name) {
+		log.Printf("[Service] CreatePullRequest: author %q is not a member of team %q", authorID, team.Name)
+		return domain.PullRequest{}, fmt.Errorf("failed to create pull request: %w", domain.ErrNotTeamMember)
+	}
+	if team.Archived() {
+		log.Printf("[Service] CreatePullRequest: team %q of author %q is archived", team.Name, authorID)
+		return domain.PullRequest{}, fmt.Errorf("failed to create pull request: %w", domain.ErrTeamArchived)
+	}
+	priority := opts.Priority
+	if priority == "" {
+		priority = domain.PriorityNormal
+	}
+
+	pr := domain.PullRequest{
+		ID:                id,
+		Name:              name,
+		AuthorID:          authorID,
+		TeamName:          team.Name,
+		Status:            domain.PullRequestStatusOpen,
+		RequiredReviewers: team.RequiredReviewers,
+		ChangedFiles:      opts.ChangedFiles,
+		RequiredTags:      domain.NormalizeTags(opts.RequiredTags),
+		Priority:          priority,
+		SizeLines:         opts.SizeLines,
+	}
+	var selected selection
+	if opts.Draft {
+		pr.Status = domain.PullRequestStatusDraft
+	} else {
+		selected, err = s.initialReviewers(ctx, author, team, pr)
+		if err != nil {
+			log.Printf("[Service] CreatePullRequest: error selecting reviewers for PR %q: %v", id, err)
+			return domain.PullRequest{}, fmt.Errorf("failed to create pull request: %w", err)
+		}
+	}
+	pr.AssignedReviewers = selected.ids()
+	pr.FallbackReviewers = selected.fallback
+
+	pr, err = s.repo.CreatePullRequest(ctx, pr)
 	if err != nil {
 		log.Printf("[Service] CreatePullRequest: error creating PR %q by author %q: %v", id, authorID, err)
 		return domain.PullRequest{}, fmt.Errorf("failed to create pull request: %w", err)
 	}
+	if opts.Draft {
+		log.Printf("[Service] CreatePullRequest: created draft PR %q, reviewers are deferred", pr.ID)
+		return pr, nil
+	}
+	s.recordAssigned(selected)
 
+	pr.Shortfall = selected.shortfall(team.RequiredReviewers)
+	if pr.Shortfall != nil {
+		log.Printf("[Service] CreatePullRequest: PR %q is short of reviewers: %s", pr.ID, pr.Shortfall.Message())
+	}
 	log.Printf("[Service] CreatePullRequest: created PR %q with %d reviewers", pr.ID, len(pr.AssignedReviewers))
 	return pr, nil
 }
 
+// initialReviewers picks the full reviewer set for a PR that has none yet.
+func (s *service) initialReviewers(ctx context.Context, author domain.User, team domain.Team, pr domain.PullRequest) (selection, error) {
+	owners, err := s.codeOwners(ctx, pr.ChangedFiles, nil)
+	if err != nil {
+		return selection{}, err
+	}
+	return s.pickReviewers(ctx, selectionRequest{
+		author: author,
+		team:   team,
+		owners: owners,
+		tags:   pr.RequiredTags,
+		count:  team.RequiredReviewers,
+		units:  pr.LoadUnits(),
+		urgent: pr.Urgent(),
+	})
+}
+
+// UpdatePullRequest renames the PR and transfers it to another author. When
+// the new author is one of the reviewers, they are replaced as if they left.
+func (s *service) UpdatePullRequest(ctx context.Context, id string, update domain.PullRequestUpdate) (domain.PullRequest, []domain.ReviewerReassignment, error) {
+	if strings.TrimSpace(id) == "" {
+		return domain.PullRequest{}, nil, errors.New("pull request ID is required")
+	}
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" {
+			return domain.PullRequest{}, nil, errors.New("pull request name must not be empty")
+		}
+		update.Name = &name
+	}
+	if update.AuthorID != nil && strings.TrimSpace(*update.AuthorID) == "" {
+		return domain.PullRequest{}, nil, errors.New("author ID must not be empty")
+	}
+
+	current, err := s.repo.GetPullRequest(ctx, id)
+	if err != nil {
+		log.Printf("[Service] UpdatePullRequest: error fetching PR %q: %v", id, err)
+		return domain.PullRequest{}, nil, fmt.Errorf("failed to update pull request: %w", err)
+	}
+
+	var moves []domain.ReviewerReassignment
+	var picked selection
+	if update.AuthorID != nil && *update.AuthorID != current.AuthorID {
+		author, team, err := s.authorTeam(ctx, *update.AuthorID, current.TeamName)
+		if err != nil {
+			log.Printf("[Service] UpdatePullRequest: error fetching new author %q and team: %v", *update.AuthorID, err)
+			return domain.PullRequest{}, nil, fmt.Errorf("failed to update pull request: %w", err)
+		}
+		if !author.InTeam(team.Name) {
+			log.Printf("[Service] UpdatePullRequest: new author %q is not a member of team %q", author.ID, team.Name)
+			return domain.PullRequest{}, nil, fmt.Errorf("failed to update pull request: %w", domain.ErrNotTeamMember)
+		}
+		leaving := map[string]struct{}{author.ID: {}}
+		if _, ok := idSet(current.AssignedReviewers)[author.ID]; ok {
+			moves, err = s.planPullRequestMoves(ctx, current, author, team, leaving, make(map[string]int), &picked)
+			if err != nil {
+				log.Printf("[Service] UpdatePullRequest: error replacing new author %q as reviewer of PR %q: %v", author.ID, id, err)
+				return domain.PullRequest{}, nil, fmt.Errorf("failed to update pull request: %w", err)
+			}
+		}
+	}
+
+	pr, err := s.repo.UpdatePullRequest(ctx, id, update, moves)
+	if err != nil {
+		log.Printf("[Service] UpdatePullRequest: error updating PR %q: %v", id, err)
+		return domain.PullRequest{}, nil, fmt.Errorf("failed to update pull request: %w", err)
+	}
+	s.recordAssigned(picked)
+
+	log.Printf("[Service] UpdatePullRequest: updated PR %q", id)
+	return pr, moves, nil
+}
+
+func (s *service) MarkPullRequestReady(ctx context.Context, id string) (domain.PullRequest, error) {
+	if strings.TrimSpace(id) == "" {
+		return domain.PullRequest{}, errors.New("pull request ID is required")
+	}
+	current, err := s.repo.GetPullRequest(ctx, id)
+	if err != nil {
+		log.Printf("[Service] MarkPullRequestReady: error fetching PR %q: %v", id, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to mark pull request ready: %w", err)
+	}
+	switch current.Status {
+	case domain.PullRequestStatusOpen:
+		log.Printf("[Service] MarkPullRequestReady: PR %q already open, returning current state", id)
+		return current, nil
+	case domain.PullRequestStatusMerged:
+		return domain.PullRequest{}, domain.ErrPRMerged
+	case domain.PullRequestStatusClosed:
+		return domain.PullRequest{}, domain.ErrPRClosed
+	}
+
+	author, team, err := s.authorTeam(ctx, current.AuthorID, current.TeamName)
+	if err != nil {
+		log.Printf("[Service] MarkPullRequestReady: error fetching author %q and team: %v", current.AuthorID, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to mark pull request ready: %w", err)
+	}
+	selected, err := s.initialReviewers(ctx, author, team, current)
+	if err != nil {
+		log.Printf("[Service] MarkPullRequestReady: error selecting reviewers for PR %q: %v", id, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to mark pull request ready: %w", err)
+	}
+
+	pr, err := s.repo.MarkPullRequestReady(ctx, id, selected.ids(), selected.fallback)
+	if err != nil {
+		log.Printf("[Service] MarkPullRequestReady: error opening PR %q: %v", id, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to mark pull request ready: %w", err)
+	}
+	s.recordAssigned(selected)
+
+	pr.Shortfall = selected.shortfall(team.RequiredReviewers)
+	if pr.Shortfall != nil {
+		log.Printf("[Service] MarkPullRequestReady: PR %q is short of reviewers: %s", pr.ID, pr.Shortfall.Message())
+	}
+	log.Printf("[Service] MarkPullRequestReady: PR %q is open with %d reviewers", pr.ID, len(pr.AssignedReviewers))
+	return pr, nil
+}
+
 func (s *service) GetPullRequest(ctx context.Context, id string) (domain.PullRequest, error) {
 	if strings.TrimSpace(id) == "" {
 		return domain.PullRequest{}, errors.New("pull request ID is required")
@@ -97,29 +646,112 @@ func (s *service) GetPullRequest(ctx context.Context, id string) (domain.PullReq
 	return pr, nil
 }
 
-func (s *service) MergePullRequest(ctx context.Context, id string) (domain.PullRequest, error) {
+func (s *service) MergePullRequest(ctx context.Context, id string, opts domain.MergeOptions) (domain.PullRequest, error) {
 	if strings.TrimSpace(id) == "" {
 		return domain.PullRequest{}, errors.New("pull request ID is required")
 	}
-	pr, err := s.repo.MergePullRequest(ctx, id)
+	current, err := s.repo.GetPullRequest(ctx, id)
 	if err != nil {
-		log.Printf("[Service] MergePullRequest: error merging PR %q: %v", id, err)
+		log.Printf("[Service] MergePullRequest: error fetching PR %q: %v", id, err)
 		return domain.PullRequest{}, fmt.Errorf("failed to merge pull request: %w", err)
 	}
-	if pr.Status == domain.PullRequestStatusMerged {
+	if current.Status == domain.PullRequestStatusMerged {
 		log.Printf("[Service] MergePullRequest: PR %q already merged, returning current state", id)
-		return pr, nil
+		return current, nil
 	}
-	mergedPR, err := s.repo.MergePullRequest(ctx, id)
+	mergedPR, err := s.repo.MergePullRequest(ctx, id, opts)
 	if err != nil {
 		log.Printf("[Service] MergePullRequest: error merging PR %q: %v", id, err)
 		return domain.PullRequest{}, fmt.Errorf("failed to merge pull request: %w", err)
 	}
+	if opts.Force {
+		log.Printf("[Service] MergePullRequest: PR %q merged with force override (%d of %d approvals)", id, mergedPR.Approvals(), mergedPR.RequiredApprovals)
+	}
 	log.Printf("[Service] MergePullRequest: successfully merged PR %q", id)
 	return mergedPR, nil
 }
 
-func (s *service) ReassignReviewer(ctx context.Context, prID, oldReviewerID string) (domain.PullRequest, string, error) {
+func (s *service) ClosePullRequest(ctx context.Context, id string) (domain.PullRequest, error) {
+	if strings.TrimSpace(id) == "" {
+		return domain.PullRequest{}, errors.New("pull request ID is required")
+	}
+	pr, err := s.repo.ClosePullRequest(ctx, id)
+	if err != nil {
+		log.Printf("[Service] ClosePullRequest: error closing PR %q: %v", id, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to close pull request: %w", err)
+	}
+	log.Printf("[Service] ClosePullRequest: PR %q is closed", id)
+	return pr, nil
+}
+
+// ReopenPullRequest keeps the reviewers the PR had when it was closed and
+// replaces only those who were deactivated in the meantime.
+func (s *service) ReopenPullRequest(ctx context.Context, id string) (domain.PullRequest, []domain.ReviewerReassignment, error) {
+	if strings.TrimSpace(id) == "" {
+		return domain.PullRequest{}, nil, errors.New("pull request ID is required")
+	}
+	current, err := s.repo.GetPullRequest(ctx, id)
+	if err != nil {
+		log.Printf("[Service] ReopenPullRequest: error fetching PR %q: %v", id, err)
+		return domain.PullRequest{}, nil, fmt.Errorf("failed to reopen pull request: %w", err)
+	}
+	switch current.Status {
+	case domain.PullRequestStatusOpen:
+		log.Printf("[Service] ReopenPullRequest: PR %q already open, returning current state", id)
+		return current, nil, nil
+	case domain.PullRequestStatusMerged:
+		log.Printf("[Service] ReopenPullRequest: cannot reopen merged PR %q", id)
+		return domain.PullRequest{}, nil, domain.ErrPRMerged
+	case domain.PullRequestStatusDraft:
+		log.Printf("[Service] ReopenPullRequest: cannot reopen draft PR %q", id)
+		return domain.PullRequest{}, nil, domain.ErrPRDraft
+	}
+
+	leaving, err := s.unfitReviewers(ctx, current)
+	if err != nil {
+		log.Printf("[Service] ReopenPullRequest: error checking reviewers of PR %q: %v", id, err)
+		return domain.PullRequest{}, nil, fmt.Errorf("failed to reopen pull request: %w", err)
+	}
+
+	var moves []domain.ReviewerReassignment
+	var picked selection
+	if len(leaving) > 0 {
+		author, team, err := s.authorTeam(ctx, current.AuthorID, current.TeamName)
+		if err != nil {
+			log.Printf("[Service] ReopenPullRequest: error fetching author %q and team: %v", current.AuthorID, err)
+			return domain.PullRequest{}, nil, fmt.Errorf("failed to reopen pull request: %w", err)
+		}
+		moves, err = s.planPullRequestMoves(ctx, current, author, team, leaving, make(map[string]int), &picked)
+		if err != nil {
+			log.Printf("[Service] ReopenPullRequest: error planning reassignments for PR %q: %v", id, err)
+			return domain.PullRequest{}, nil, fmt.Errorf("failed to reopen pull request: %w", err)
+		}
+	}
+
+	pr, err := s.repo.ReopenPullRequest(ctx, id, moves)
+	if err != nil {
+		log.Printf("[Service] ReopenPullRequest: error reopening PR %q: %v", id, err)
+		return domain.PullRequest{}, nil, fmt.Errorf("failed to reopen pull request: %w", err)
+	}
+	s.recordAssigned(picked)
+
+	log.Printf("[Service] ReopenPullRequest: PR %q reopened, %d reviewers replaced", id, len(picked.reviewers))
+	return pr, moves, nil
+}
+
+func (s *service) ListForcedMerges(ctx context.Context) ([]domain.ForcedMerge, error) {
+	merges, err := s.repo.ListForcedMerges(ctx)
+	if err != nil {
+		log.Printf("[Service] ListForcedMerges: error fetching forced merges: %v", err)
+		return nil, fmt.Errorf("failed to list forced merges: %w", err)
+	}
+	return merges, nil
+}
+
+// ReassignReviewer replaces oldReviewerID with newReviewerID when it is given
+// and passes the eligibility checks, otherwise with an automatically selected
+// candidate.
+func (s *service) ReassignReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) (domain.PullRequest, string, error) {
 	if strings.TrimSpace(prID) == "" {
 		return domain.PullRequest{}, "", errors.New("pull request ID is required")
 	}
@@ -135,16 +767,190 @@ func (s *service) ReassignReviewer(ctx context.Context, prID, oldReviewerID stri
 		log.Printf("[Service] ReassignReviewer: cannot reassign on merged PR %q", prID)
 		return pr, "", domain.ErrPRMerged
 	}
-	updatedPR, replacement, err := s.repo.ReassignReviewer(ctx, prID, oldReviewerID)
+	if pr.Status == domain.PullRequestStatusClosed {
+		log.Printf("[Service] ReassignReviewer: cannot reassign on closed PR %q", prID)
+		return pr, "", domain.ErrPRClosed
+	}
+	if _, ok := idSet(pr.AssignedReviewers)[oldReviewerID]; !ok {
+		log.Printf("[Service] ReassignReviewer: user %q is not assigned to PR %q", oldReviewerID, prID)
+		return domain.PullRequest{}, "", domain.ErrNotAssigned
+	}
+	if newReviewerID != "" {
+		return s.reassignTo(ctx, pr, oldReviewerID, newReviewerID)
+	}
+	selected, err := s.pickReplacement(ctx, pr, oldReviewerID)
+	if err != nil {
+		log.Printf("[Service] ReassignReviewer: error selecting candidate for PR %q: %v", prID, err)
+		return domain.PullRequest{}, "", fmt.Errorf("failed to reassign reviewer: %w", err)
+	}
+	if len(selected.reviewers) == 0 {
+		log.Printf("[Service] ReassignReviewer: no candidate to replace %q in PR %q", oldReviewerID, prID)
+		if len(selected.atCapacity) > 0 {
+			return domain.PullRequest{}, "", domain.ErrAtCapacity
+		}
+		return domain.PullRequest{}, "", domain.ErrNoCandidate
+	}
+	replacement := selected.reviewers[0].ID
+
+	updatedPR, err := s.repo.ReassignReviewer(ctx, prID, oldReviewerID, replacement, selected.fallback[replacement])
 	if err != nil {
 		log.Printf("[Service] ReassignReviewer: error reassigning reviewer in PR %q: %v", prID, err)
 		return domain.PullRequest{}, "", fmt.Errorf("failed to reassign reviewer: %w", err)
 	}
 
+	s.recordAssigned(selected)
+
 	log.Printf("[Service] ReassignReviewer: replaced %q with %q in PR %q", oldReviewerID, replacement, prID)
 	return updatedPR, replacement, nil
 }
 
+// pickReplacement selects one candidate for oldReviewerID with the PR's
+// owners and tags in mind, the remaining reviewers stay as they are.
+func (s *service) pickReplacement(ctx context.Context, pr domain.PullRequest, oldReviewerID string) (selection, error) {
+	author, team, err := s.authorTeam(ctx, pr.AuthorID, pr.TeamName)
+	if err != nil {
+		return selection{}, err
+	}
+	var staying []string
+	for _, id := range pr.AssignedReviewers {
+		if id != oldReviewerID {
+			staying = append(staying, id)
+		}
+	}
+	owners, err := s.codeOwners(ctx, pr.ChangedFiles, staying)
+	if err != nil {
+		return selection{}, err
+	}
+	covered, err := s.tagsOf(ctx, pr.RequiredTags, staying)
+	if err != nil {
+		return selection{}, err
+	}
+	return s.pickReviewers(ctx, selectionRequest{
+		author:   author,
+		team:     team,
+		owners:   owners,
+		tags:     pr.RequiredTags,
+		covered:  covered,
+		excluded: idSet(pr.AssignedReviewers),
+		count:    1,
+		units:    pr.LoadUnits(),
+		urgent:   pr.Urgent(),
+	})
+}
+
+func (s *service) reassignTo(ctx context.Context, pr domain.PullRequest, oldReviewerID, newReviewerID string) (domain.PullRequest, string, error) {
+	fallbackTeam, err := s.checkEligible(ctx, pr, newReviewerID)
+	if err != nil {
+		log.Printf("[Service] ReassignReviewer: user %q cannot review PR %q: %v", newReviewerID, pr.ID, err)
+		return domain.PullRequest{}, "", fmt.Errorf("failed to reassign reviewer: %w", err)
+	}
+	updatedPR, err := s.repo.ReassignReviewer(ctx, pr.ID, oldReviewerID, newReviewerID, fallbackTeam)
+	if err != nil {
+		log.Printf("[Service] ReassignReviewer: error reassigning reviewer in PR %q: %v", pr.ID, err)
+		return domain.PullRequest{}, "", fmt.Errorf("failed to reassign reviewer: %w", err)
+	}
+	log.Printf("[Service] ReassignReviewer: replaced %q with requested %q in PR %q", oldReviewerID, newReviewerID, pr.ID)
+	return updatedPR, newReviewerID, nil
+}
+
+func (s *service) SubmitReview(ctx context.Context, prID, reviewerID string, state domain.ReviewState) (domain.PullRequest, error) {
+	if strings.TrimSpace(prID) == "" {
+		return domain.PullRequest{}, errors.New("pull request ID is required")
+	}
+	if strings.TrimSpace(reviewerID) == "" {
+		return domain.PullRequest{}, errors.New("reviewer ID is required")
+	}
+	if !state.IsVerdict() {
+		log.Printf("[Service] SubmitReview: validation error - invalid state %q", state)
+		return domain.PullRequest{}, domain.ErrInvalidReviewState
+	}
+	pr, err := s.repo.SubmitReview(ctx, prID, reviewerID, state)
+	if err != nil {
+		log.Printf("[Service] SubmitReview: error submitting review of %q on PR %q: %v", reviewerID, prID, err)
+		return domain.PullRequest{}, fmt.Errorf("failed to submit review: %w", err)
+	}
+	log.Printf("[Service] SubmitReview: %q set %s on PR %q", reviewerID, state, prID)
+	return pr, nil
+}
+
+func (s *service) BackfillReviewers(ctx context.Context) ([]domain.ReviewerBackfill, error) {
+	prs, err := s.repo.ListUnderstaffedPullRequests(ctx)
+	if err != nil {
+		log.Printf("[Service] BackfillReviewers: error listing understaffed PRs: %v", err)
+		return nil, fmt.Errorf("failed to backfill reviewers: %w", err)
+	}
+
+	var result []domain.ReviewerBackfill
+	for _, pr := range prs {
+		author, team, err := s.authorTeam(ctx, pr.AuthorID, pr.TeamName)
+		if err != nil {
+			log.Printf("[Service] BackfillReviewers: error fetching author of PR %q and team: %v", pr.ID, err)
+			return result, fmt.Errorf("failed to backfill reviewers: %w", err)
+		}
+		owners, err := s.codeOwners(ctx, pr.ChangedFiles, pr.AssignedReviewers)
+		if err != nil {
+			log.Printf("[Service] BackfillReviewers: error resolving code owners for PR %q: %v", pr.ID, err)
+			return result, fmt.Errorf("failed to backfill reviewers: %w", err)
+		}
+		covered, err := s.tagsOf(ctx, pr.RequiredTags, pr.AssignedReviewers)
+		if err != nil {
+			log.Printf("[Service] BackfillReviewers: error loading reviewer tags for PR %q: %v", pr.ID, err)
+			return result, fmt.Errorf("failed to backfill reviewers: %w", err)
+		}
+		selected, err := s.pickReviewers(ctx, selectionRequest{
+			author:   author,
+			team:     team,
+			owners:   owners,
+			tags:     pr.RequiredTags,
+			covered:  covered,
+			excluded: idSet(pr.AssignedReviewers),
+			count:    pr.RequiredReviewers - len(pr.AssignedReviewers),
+			units:    pr.LoadUnits(),
+			urgent:   pr.Urgent(),
+		})
+		if err != nil {
+			log.Printf("[Service] BackfillReviewers: error selecting reviewers for PR %q: %v", pr.ID, err)
+			return result, fmt.Errorf("failed to backfill reviewers: %w", err)
+		}
+		if len(selected.reviewers) == 0 {
+			continue
+		}
+
+		added := selected.ids()
+		updated, err := s.repo.AddReviewers(ctx, pr.ID, added, selected.fallback, pr.RequiredReviewers)
+		if assignmentConflict(err) {
+			log.Printf("[Service] BackfillReviewers: PR %q changed since selection, skipping: %v", pr.ID, err)
+			continue
+		}
+		if err != nil {
+			log.Printf("[Service] BackfillReviewers: error adding reviewers to PR %q: %v", pr.ID, err)
+			return result, fmt.Errorf("failed to backfill reviewers: %w", err)
+		}
+		s.recordAssigned(selected)
+		updated.Shortfall = selected.shortfall(pr.RequiredReviewers - len(pr.AssignedReviewers))
+		result = append(result, domain.ReviewerBackfill{PullRequest: updated, AddedReviewers: added})
+	}
+
+	log.Printf("[Service] BackfillReviewers: checked %d PRs, updated %d", len(prs), len(result))
+	return result, nil
+}
+
+// assignmentConflict reports whether adding reviewers failed because the PR or
+// a picked reviewer changed after the selection. Backfill leaves such a PR to
+// the next run.
+func assignmentConflict(err error) bool {
+	for _, target := range []error{
+		domain.ErrPRMerged, domain.ErrPRClosed, domain.ErrPRDraft, domain.ErrEnoughReviewers,
+		domain.ErrAlreadyAssigned, domain.ErrReviewerIsAuthor, domain.ErrUserInactive,
+		domain.ErrUserUnavailable, domain.ErrAtCapacity,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *service) ListReviewerPullRequests(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
 	if strings.TrimSpace(userID) == "" {
 		return nil, errors.New("user ID is required")
@@ -159,8 +965,16 @@ func (s *service) ListReviewerPullRequests(ctx context.Context, userID string) (
 	return prs, nil
 }
 
-func (s *service) GetReviewerStats(ctx context.Context) ([]repository.ReviewerStats, error) {
-	stats, err := s.repo.GetReviewerStats(ctx)
+// GetReviewerStats and GetPRStats cover the whole service, or only one team
+// when teamName is set, together with all teams below it when subtree is set.
+// Archived teams stay queryable.
+func (s *service) GetReviewerStats(ctx context.Context, teamName string, subtree bool) ([]repository.ReviewerStats, error) {
+	teams, err := s.statsTeams(ctx, teamName, subtree)
+	if err != nil {
+		log.Printf("[Service] GetReviewerStats: failed to resolve teams of %q: %v", teamName, err)
+		return nil, fmt.Errorf("failed to get reviewer stats: %w", err)
+	}
+	stats, err := s.repo.GetReviewerStats(ctx, teams)
 	if err != nil {
 		log.Printf("[Service] GetReviewerStats: error fetching reviewer stats: %v", err)
 		return nil, fmt.Errorf("failed to get reviewer stats: %w", err)
@@ -169,8 +983,13 @@ func (s *service) GetReviewerStats(ctx context.Context) ([]repository.ReviewerSt
 	return stats, nil
 }
 
-func (s *service) GetPRStats(ctx context.Context) (repository.PRStats, error) {
-	stats, err := s.repo.GetPRStats(ctx)
+func (s *service) GetPRStats(ctx context.Context, teamName string, subtree bool) (repository.PRStats, error) {
+	teams, err := s.statsTeams(ctx, teamName, subtree)
+	if err != nil {
+		log.Printf("[Service] GetPRStats: failed to resolve teams of %q: %v", teamName, err)
+		return repository.PRStats{}, fmt.Errorf("failed to get PR stats: %w", err)
+	}
+	stats, err := s.repo.GetPRStats(ctx, teams)
 	if err != nil {
 		log.Printf("[Service] GetPRStats: error fetching PR stats: %v", err)
 		return repository.PRStats{}, fmt.Errorf("failed to get PR stats: %w", err)
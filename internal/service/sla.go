@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
+)
+
+func (s *service) ListOverdueReviews(ctx context.Context, at time.Time) ([]domain.PendingReview, error) {
+	pending, err := s.repo.ListPendingReviews(ctx)
+	if err != nil {
+		log.Printf("[Service] ListOverdueReviews: error listing pending reviews: %v", err)
+		return nil, fmt.Errorf("failed to list overdue reviews: %w", err)
+	}
+	var overdue []domain.PendingReview
+	for _, p := range pending {
+		p.Workday = s.workday
+		if p.Overdue(at) {
+			overdue = append(overdue, p)
+		}
+	}
+	return overdue, nil
+}
+
+// EscalateOverdueReviews applies the team SLA action to every overdue
+// assignment that was not escalated yet. Assignments without a candidate are
+// reported with a reason and retried on the next run.
+func (s *service) EscalateOverdueReviews(ctx context.Context, at time.Time) ([]domain.SLAEscalation, error) {
+	overdue, err := s.ListOverdueReviews(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []domain.SLAEscalation
+	for _, item := range overdue {
+		if item.EscalatedAt != nil {
+			continue
+		}
+		escalation := domain.SLAEscalation{
+			PullRequestID: item.PullRequestID,
+			ReviewerID:    item.ReviewerID,
+			Action:        item.Action,
+		}
+
+		pr, err := s.repo.GetPullRequest(ctx, item.PullRequestID)
+		if err != nil {
+			log.Printf("[Service] EscalateOverdueReviews: error fetching PR %q: %v", item.PullRequestID, err)
+			return result, fmt.Errorf("failed to escalate overdue reviews: %w", err)
+		}
+		leaving := ""
+		if item.Action == domain.SLAActionReassign {
+			leaving = item.ReviewerID
+		}
+		selected, err := s.pickReplacement(ctx, pr, leaving)
+		if err != nil {
+			log.Printf("[Service] EscalateOverdueReviews: error selecting candidate for PR %q: %v", item.PullRequestID, err)
+			return result, fmt.Errorf("failed to escalate overdue reviews: %w", err)
+		}
+		if len(selected.reviewers) == 0 {
+			escalation.Reason = domain.ErrNoCandidate.Error()
+			if len(selected.atCapacity) > 0 {
+				escalation.Reason = domain.ErrAtCapacity.Error()
+			}
+			log.Printf("[Service] EscalateOverdueReviews: no candidate for overdue review of %q on PR %q", item.ReviewerID, item.PullRequestID)
+			result = append(result, escalation)
+			continue
+		}
+
+		escalation.NewReviewerID = selected.reviewers[0].ID
+		_, err = s.repo.EscalateReview(ctx, escalation, selected.fallback[escalation.NewReviewerID])
+		if errors.Is(err, domain.ErrNotAssigned) || errors.Is(err, domain.ErrAlreadyEscalated) ||
+			errors.Is(err, domain.ErrPRMerged) || errors.Is(err, domain.ErrPRClosed) {
+			continue
+		}
+		if err != nil {
+			log.Printf("[Service] EscalateOverdueReviews: error escalating review of %q on PR %q: %v", item.ReviewerID, item.PullRequestID, err)
+			return result, fmt.Errorf("failed to escalate overdue reviews: %w", err)
+		}
+		s.recordAssigned(selected)
+		log.Printf("[Service] EscalateOverdueReviews: %s on PR %q: %q overdue, %q assigned", item.Action, item.PullRequestID, item.ReviewerID, escalation.NewReviewerID)
+		result = append(result, escalation)
+	}
+
+	return result, nil
+}
+
+// RunSLAWorker escalates overdue reviews every interval until ctx is done.
+func RunSLAWorker(ctx context.Context, svc Service, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("[SLAWorker] disabled")
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := svc.EscalateOverdueReviews(ctx, time.Now()); err != nil {
+				log.Printf("[SLAWorker] escalation run failed: %v", err)
+			}
+		}
+	}
+}
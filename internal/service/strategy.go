@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+
+	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
+)
+
+const (
+	StrategyRandom      = "random"
+	StrategyLeastLoaded = "least_loaded"
+	StrategyRoundRobin  = "round_robin"
+)
+
+// ReviewerStrategy ranks the author's teammates for review. Rank returns
+// eligible reviewers (active, not the author) best first; Assigned is called
+// with the reviewers that were actually picked from that ranking.
+type ReviewerStrategy interface {
+	Rank(author domain.User, roster []domain.User, load map[string]int) []domain.User
+	Assigned(reviewerIDs []string)
+}
+
+func NewStrategy(name string) (ReviewerStrategy, error) {
+	switch name {
+	case StrategyRandom:
+		return randomStrategy{}, nil
+	case StrategyLeastLoaded:
+		return leastLoadedStrategy{}, nil
+	case StrategyRoundRobin:
+		return newRoundRobinStrategy(), nil
+	default:
+		return nil, fmt.Errorf("unknown reviewer strategy %q", name)
+	}
+}
+
+type Strategies struct {
+	fallback ReviewerStrategy
+
+	mu    sync.RWMutex
+	teams map[string]ReviewerStrategy
+}
+
+func NewStrategies(defaultName string, teamNames map[string]string) (*Strategies, error) {
+	fallback, err := NewStrategy(defaultName)
+	if err != nil {
+		return nil, err
+	}
+	s := &Strategies{fallback: fallback, teams: make(map[string]ReviewerStrategy, len(teamNames))}
+	for team, name := range teamNames {
+		strategy, err := NewStrategy(name)
+		if err != nil {
+			return nil, fmt.Errorf("team %q: %w", team, err)
+		}
+		s.teams[team] = strategy
+	}
+	return s, nil
+}
+
+func (s *Strategies) For(teamName string) ReviewerStrategy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if strategy, ok := s.teams[teamName]; ok {
+		return strategy
+	}
+	return s.fallback
+}
+
+// Teams returns the names of the teams with their own strategy, sorted.
+func (s *Strategies) Teams() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.teams))
+	for name := range s.teams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rename moves the strategy configured for oldName to newName and reports
+// whether there was one. The move only lasts until restart, the
+// configuration still names the old team.
+func (s *Strategies) Rename(oldName, newName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	strategy, ok := s.teams[oldName]
+	if !ok {
+		return false
+	}
+	delete(s.teams, oldName)
+	s.teams[newName] = strategy
+	return true
+}
+
+func eligibleReviewers(author domain.User, roster []domain.User) []domain.User {
+	out := make([]domain.User, 0, len(roster))
+	for _, u := range roster {
+		if u.IsActive && u.ID != author.ID {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+func reviewerIDs(users []domain.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+type randomStrategy struct{}
+
+func (randomStrategy) Rank(author domain.User, roster []domain.User, _ map[string]int) []domain.User {
+	out := eligibleReviewers(author, roster)
+	rand.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+	return out
+}
+
+func (randomStrategy) Assigned([]string) {}
+
+type leastLoadedStrategy struct{}
+
+func (leastLoadedStrategy) Rank(author domain.User, roster []domain.User, load map[string]int) []domain.User {
+	out := randomStrategy{}.Rank(author, roster, load)
+	sort.SliceStable(out, func(i, j int) bool {
+		return load[out[i].ID] < load[out[j].ID]
+	})
+	return out
+}
+
+func (leastLoadedStrategy) Assigned([]string) {}
+
+type roundRobinStrategy struct {
+	mu   sync.Mutex
+	tick uint64
+	last map[string]uint64
+}
+
+func newRoundRobinStrategy() *roundRobinStrategy {
+	return &roundRobinStrategy{last: make(map[string]uint64)}
+}
+
+func (s *roundRobinStrategy) Rank(author domain.User, roster []domain.User, _ map[string]int) []domain.User {
+	out := eligibleReviewers(author, roster)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sort.SliceStable(out, func(i, j int) bool {
+		li, lj := s.last[out[i].ID], s.last[out[j].ID]
+		if li != lj {
+			return li < lj
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+func (s *roundRobinStrategy) Assigned(reviewerIDs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range reviewerIDs {
+		s.tick++
+		s.last[id] = s.tick
+	}
+}
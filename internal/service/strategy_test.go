@@ -0,0 +1,146 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
+)
+
+func testRoster() []domain.User {
+	return []domain.User{
+		{ID: "u1", IsActive: true},
+		{ID: "u2", IsActive: true},
+		{ID: "u3", IsActive: true},
+		{ID: "u4", IsActive: false},
+	}
+}
+
+func TestStrategies_Eligibility(t *testing.T) {
+	for _, name := range []string{StrategyRandom, StrategyLeastLoaded, StrategyRoundRobin} {
+		t.Run(name, func(t *testing.T) {
+			strategy, err := NewStrategy(name)
+			if err != nil {
+				t.Fatalf("NewStrategy(%q) error = %v", name, err)
+			}
+			ranked := strategy.Rank(domain.User{ID: "u1"}, testRoster(), nil)
+			if len(ranked) != 2 {
+				t.Fatalf("Rank() returned %d reviewers, want 2", len(ranked))
+			}
+			for _, u := range ranked {
+				if u.ID == "u1" || u.ID == "u4" {
+					t.Errorf("Rank() returned ineligible reviewer %q", u.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestLeastLoadedStrategy_Rank(t *testing.T) {
+	load := map[string]int{"u2": 3, "u3": 1}
+	ranked := leastLoadedStrategy{}.Rank(domain.User{ID: "u1"}, testRoster(), load)
+	if got := reviewerIDs(ranked); len(got) != 2 || got[0] != "u3" || got[1] != "u2" {
+		t.Errorf("Rank() = %v, want [u3 u2]", got)
+	}
+}
+
+func TestRoundRobinStrategy_Rank(t *testing.T) {
+	strategy := newRoundRobinStrategy()
+	roster := append(testRoster(), domain.User{ID: "u5", IsActive: true})
+	author := domain.User{ID: "u1"}
+
+	var picked []string
+	for i := 0; i < 3; i++ {
+		first := strategy.Rank(author, roster, nil)[0].ID
+		strategy.Assigned([]string{first})
+		picked = append(picked, first)
+	}
+
+	want := []string{"u2", "u3", "u5"}
+	for i := range want {
+		if picked[i] != want[i] {
+			t.Fatalf("picked = %v, want %v", picked, want)
+		}
+	}
+}
+
+func TestNewStrategies(t *testing.T) {
+	strategies, err := NewStrategies(StrategyLeastLoaded, map[string]string{"backend": StrategyRoundRobin})
+	if err != nil {
+		t.Fatalf("NewStrategies() error = %v", err)
+	}
+	if _, ok := strategies.For("backend").(*roundRobinStrategy); !ok {
+		t.Errorf("For(backend) = %T, want *roundRobinStrategy", strategies.For("backend"))
+	}
+	if _, ok := strategies.For("frontend").(leastLoadedStrategy); !ok {
+		t.Errorf("For(frontend) = %T, want leastLoadedStrategy", strategies.For("frontend"))
+	}
+
+	if _, err := NewStrategies("fastest", nil); err == nil {
+		t.Error("NewStrategies() with unknown default: expected error")
+	}
+	if _, err := NewStrategies(StrategyRandom, map[string]string{"backend": "fastest"}); err == nil {
+		t.Error("NewStrategies() with unknown team strategy: expected error")
+	}
+}
+
+func TestStrategies_Rename(t *testing.T) {
+	strategies, err := NewStrategies(StrategyLeastLoaded, map[string]string{"backend": StrategyRoundRobin})
+	if err != nil {
+		t.Fatalf("NewStrategies() error = %v", err)
+	}
+	if !strategies.Rename("backend", "core") {
+		t.Fatal("Rename(backend, core) = false, want true")
+	}
+	if _, ok := strategies.For("core").(*roundRobinStrategy); !ok {
+		t.Errorf("For(core) = %T, want *roundRobinStrategy", strategies.For("core"))
+	}
+	if _, ok := strategies.For("backend").(leastLoadedStrategy); !ok {
+		t.Errorf("For(backend) = %T, want leastLoadedStrategy", strategies.For("backend"))
+	}
+	if strategies.Rename("frontend", "web") {
+		t.Error("Rename(frontend, web) = true, want false")
+	}
+}
+
+func TestStrategies_Teams(t *testing.T) {
+	strategies, err := NewStrategies(StrategyLeastLoaded, map[string]string{"web": StrategyRandom, "backend": StrategyRoundRobin})
+	if err != nil {
+		t.Fatalf("NewStrategies() error = %v", err)
+	}
+	got := strategies.Teams()
+	if len(got) != 2 || got[0] != "backend" || got[1] != "web" {
+		t.Errorf("Teams() = %v, want [backend web]", got)
+	}
+}
+
+func TestPreferTagged(t *testing.T) {
+	ranked := []domain.User{
+		{ID: "u1", Tags: []string{"go"}},
+		{ID: "u2"},
+		{ID: "u3", Tags: []string{"frontend"}},
+		{ID: "u4", Tags: []string{"frontend", "sql"}},
+	}
+
+	tests := []struct {
+		name    string
+		tags    []string
+		covered []string
+		want    []string
+	}{
+		{name: "no tags keeps strategy order", want: []string{"u1", "u2", "u3", "u4"}},
+		{name: "widest coverage first", tags: []string{"frontend", "sql"}, want: []string{"u4", "u1", "u2", "u3"}},
+		{name: "tags spread over candidates", tags: []string{"go", "sql"}, want: []string{"u1", "u4", "u2", "u3"}},
+		{name: "covered tags are ignored", tags: []string{"go", "frontend"}, covered: []string{"go"}, want: []string{"u3", "u1", "u2", "u4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reviewerIDs(preferTagged(ranked, tt.tags, idSet(tt.covered)))
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("preferTagged() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
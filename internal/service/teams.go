@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
+)
+
+func (s *service) AddTeamMembers(ctx context.Context, teamName string, members []domain.User, allowMoves bool) (domain.Team, error) {
+	if strings.TrimSpace(teamName) == "" {
+		log.Printf("[Service] AddTeamMembers: validation error - team name is required")
+		return domain.Team{}, errors.New("team name is required")
+	}
+	if len(members) == 0 {
+		log.Printf("[Service] AddTeamMembers: validation error - no members for team %q", teamName)
+		return domain.Team{}, errors.New("at least one member is required")
+	}
+	seen := make(map[string]struct{}, len(members))
+	for i := range members {
+		if strings.TrimSpace(members[i].ID) == "" {
+			log.Printf("[Service] AddTeamMembers: validation error - member %d has no user ID", i)
+			return domain.Team{}, fmt.Errorf("%w: user ID is required", domain.ErrInvalidMembers)
+		}
+		if _, dup := seen[members[i].ID]; dup {
+			log.Printf("[Service] AddTeamMembers: validation error - user %q is listed twice", members[i].ID)
+			return domain.Team{}, fmt.Errorf("%w: user %q is listed twice", domain.ErrInvalidMembers, members[i].ID)
+		}
+		seen[members[i].ID] = struct{}{}
+		if members[i].MaxOpenReviews < 0 {
+			log.Printf("[Service] AddTeamMembers: validation error - invalid review limit for %q", members[i].ID)
+			return domain.Team{}, errors.New("max open reviews must not be negative")
+		}
+		if members[i].Tags != nil {
+			members[i].Tags = domain.NormalizeTags(members[i].Tags)
+		}
+	}
+
+	team, err := s.repo.AddTeamMembers(ctx, teamName, members, allowMoves)
+	if err != nil {
+		log.Printf("[Service] AddTeamMembers: failed to add members to team %q: %v", teamName, err)
+		return domain.Team{}, fmt.Errorf("failed to add team members: %w", err)
+	}
+	log.Printf("[Service] AddTeamMembers: added %d members to team %q", len(members), teamName)
+	return team, nil
+}
+
+// RemoveTeamMembers takes members out of the team. Their OPEN reviews on
+// the team's PRs, or all of them when it is their only team, are either
+// moved to other candidates, or, when reassign is false, block the removal
+// with ErrMemberHasOpenReviews.
+func (s *service) RemoveTeamMembers(ctx context.Context, teamName string, userIDs []string, reassign bool) (domain.Team, []domain.ReviewerReassignment, error) {
+	if strings.TrimSpace(teamName) == "" {
+		log.Printf("[Service] RemoveTeamMembers: validation error - team name is required")
+		return domain.Team{}, nil, errors.New("team name is required")
+	}
+	if len(userIDs) == 0 {
+		log.Printf("[Service] RemoveTeamMembers: validation error - no members for team %q", teamName)
+		return domain.Team{}, nil, errors.New("at least one user ID is required")
+	}
+	team, err := s.repo.GetTeam(ctx, teamName)
+	if err != nil {
+		log.Printf("[Service] RemoveTeamMembers: failed to get team %q: %v", teamName, err)
+		return domain.Team{}, nil, fmt.Errorf("failed to remove team members: %w", err)
+	}
+
+	members := make(map[string]domain.User, len(team.Members))
+	for _, member := range team.Members {
+		members[member.ID] = member
+	}
+	targets := make([]string, 0, len(userIDs))
+	leaving := make([]domain.User, 0, len(userIDs))
+	for id := range idSet(userIDs) {
+		member, ok := members[id]
+		if !ok {
+			log.Printf("[Service] RemoveTeamMembers: user %q is not a member of team %q", id, teamName)
+			return domain.Team{}, nil, fmt.Errorf("user %q is not a member of team %q: %w", id, teamName, domain.ErrUserNotFound)
+		}
+		targets = append(targets, id)
+		leaving = append(leaving, member)
+	}
+
+	var moves []domain.ReviewerReassignment
+	var picked selection
+	if reassign {
+		moves, picked, err = s.planReassignments(ctx, leavingTeam(leaving, teamName))
+		if err != nil {
+			log.Printf("[Service] RemoveTeamMembers: failed to plan reassignments for team %q: %v", teamName, err)
+			return domain.Team{}, nil, fmt.Errorf("failed to remove team members: %w", err)
+		}
+	}
+	updated, err := s.repo.RemoveTeamMembers(ctx, teamName, targets, moves)
+	if err != nil {
+		log.Printf("[Service] RemoveTeamMembers: failed to remove members from team %q: %v", teamName, err)
+		return domain.Team{}, nil, fmt.Errorf("failed to remove team members: %w", err)
+	}
+	s.recordAssigned(picked)
+	log.Printf("[Service] RemoveTeamMembers: removed %d members from team %q, %d open reviews affected", len(targets), teamName, len(moves))
+	return updated, moves, nil
+}
+
+func (s *service) RenameTeam(ctx context.Context, oldName, newName string) (domain.Team, error) {
+	if strings.TrimSpace(oldName) == "" || strings.TrimSpace(newName) == "" {
+		log.Printf("[Service] RenameTeam: validation error - team names are required")
+		return domain.Team{}, errors.New("team name is required")
+	}
+	if oldName == newName {
+		return s.GetTeam(ctx, oldName)
+	}
+	team, err := s.repo.RenameTeam(ctx, oldName, newName)
+	if err != nil {
+		log.Printf("[Service] RenameTeam: failed to rename team %q to %q: %v", oldName, newName, err)
+		return domain.Team{}, fmt.Errorf("failed to rename team: %w", err)
+	}
+	if s.strategies.Rename(oldName, newName) {
+		log.Printf("[Service] RenameTeam: WARNING - TEAM_REVIEWER_SELECTION names team %q, rename it to %q there or the team falls back to the default strategy after restart", oldName, newName)
+	}
+	log.Printf("[Service] RenameTeam: renamed team %q to %q", oldName, newName)
+	return team, nil
+}
+
+// MoveUser makes another team the user's primary team. With reassign, the
+// user's OPEN reviews on PRs of the old team are handed to candidates picked
+// the usual way for those PRs: the old team first, then its fallback and
+// parent teams. Reviews they do as a fallback or code owner reviewer stay.
+func (s *service) MoveUser(ctx context.Context, userID, teamName string, reassign bool) (domain.User, []domain.ReviewerReassignment, error) {
+	if strings.TrimSpace(userID) == "" {
+		log.Printf("[Service] MoveUser: validation error - user ID is required")
+		return domain.User{}, nil, errors.New("user ID is required")
+	}
+	if strings.TrimSpace(teamName) == "" {
+		log.Printf("[Service] MoveUser: validation error - team name is required")
+		return domain.User{}, nil, errors.New("team name is required")
+	}
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		log.Printf("[Service] MoveUser: failed to get user %q: %v", userID, err)
+		return domain.User{}, nil, fmt.Errorf("failed to move user: %w", err)
+	}
+	if user.TeamName == teamName {
+		log.Printf("[Service] MoveUser: user %q already in team %q", userID, teamName)
+		return user, nil, nil
+	}
+
+	var moves []domain.ReviewerReassignment
+	var picked selection
+	if reassign && user.TeamName != "" {
+		moves, picked, err = s.planReassignments(ctx, map[string]string{userID: user.TeamName})
+		if err != nil {
+			log.Printf("[Service] MoveUser: failed to plan reassignments for %q: %v", userID, err)
+			return domain.User{}, nil, fmt.Errorf("failed to move user: %w", err)
+		}
+	}
+	moved, move, err := s.repo.MoveUser(ctx, userID, teamName, moves)
+	if err != nil {
+		log.Printf("[Service] MoveUser: failed to move user %q to team %q: %v", userID, teamName, err)
+		return domain.User{}, nil, fmt.Errorf("failed to move user: %w", err)
+	}
+	s.recordAssigned(picked)
+	log.Printf("[Service] MoveUser: moved user %q from %q to %q, %d open reviews reassigned", userID, move.FromTeam, move.ToTeam, move.Reassigned)
+	return moved, moves, nil
+}
+
+// JoinTeam adds the user to one more team without leaving the current ones.
+func (s *service) JoinTeam(ctx context.Context, userID, teamName string) (domain.User, error) {
+	if strings.TrimSpace(userID) == "" {
+		log.Printf("[Service] JoinTeam: validation error - user ID is required")
+		return domain.User{}, errors.New("user ID is required")
+	}
+	if strings.TrimSpace(teamName) == "" {
+		log.Printf("[Service] JoinTeam: validation error - team name is required")
+		return domain.User{}, errors.New("team name is required")
+	}
+	user, err := s.repo.JoinTeam(ctx, userID, teamName)
+	if err != nil {
+		log.Printf("[Service] JoinTeam: failed to add user %q to team %q: %v", userID, teamName, err)
+		return domain.User{}, fmt.Errorf("failed to join team: %w", err)
+	}
+	log.Printf("[Service] JoinTeam: user %q is now in teams %v", userID, user.Teams)
+	return user, nil
+}
+
+func (s *service) ListTeamMoves(ctx context.Context, userID string) ([]domain.TeamMove, error) {
+	if strings.TrimSpace(userID) == "" {
+		log.Printf("[Service] ListTeamMoves: validation error - user ID is required")
+		return nil, errors.New("user ID is required")
+	}
+	if _, err := s.repo.GetUser(ctx, userID); err != nil {
+		log.Printf("[Service] ListTeamMoves: failed to get user %q: %v", userID, err)
+		return nil, fmt.Errorf("failed to list team moves: %w", err)
+	}
+	moves, err := s.repo.ListTeamMoves(ctx, userID)
+	if err != nil {
+		log.Printf("[Service] ListTeamMoves: failed to list moves of %q: %v", userID, err)
+		return nil, fmt.Errorf("failed to list team moves: %w", err)
+	}
+	return moves, nil
+}
+
+// ArchiveTeam stops PRs from being opened for the team and drops the team
+// from candidate pools. With reassign, the members' OPEN reviews on the
+// team's PRs, or all of them for members without another team, are handed to
+// other candidates first; without it the reviews stay where they are.
+func (s *service) ArchiveTeam(ctx context.Context, teamName string, reassign bool) (domain.Team, []domain.ReviewerReassignment, error) {
+	if strings.TrimSpace(teamName) == "" {
+		log.Printf("[Service] ArchiveTeam: validation error - team name is required")
+		return domain.Team{}, nil, errors.New("team name is required")
+	}
+	team, err := s.repo.GetTeam(ctx, teamName)
+	if err != nil {
+		log.Printf("[Service] ArchiveTeam: failed to get team %q: %v", teamName, err)
+		return domain.Team{}, nil, fmt.Errorf("failed to archive team: %w", err)
+	}
+
+	var moves []domain.ReviewerReassignment
+	var picked selection
+	if reassign && !team.Archived() && len(team.Members) > 0 {
+		moves, picked, err = s.planReassignments(ctx, leavingTeam(team.Members, teamName))
+		if err != nil {
+			log.Printf("[Service] ArchiveTeam: failed to plan reassignments for team %q: %v", teamName, err)
+			return domain.Team{}, nil, fmt.Errorf("failed to archive team: %w", err)
+		}
+	}
+	archived, err := s.repo.ArchiveTeam(ctx, teamName, moves)
+	if err != nil {
+		log.Printf("[Service] ArchiveTeam: failed to archive team %q: %v", teamName, err)
+		return domain.Team{}, nil, fmt.Errorf("failed to archive team: %w", err)
+	}
+	s.recordAssigned(picked)
+	log.Printf("[Service] ArchiveTeam: archived team %q, %d open reviews reassigned", teamName, len(moves))
+	return archived, moves, nil
+}
+
+func (s *service) UnarchiveTeam(ctx context.Context, teamName string) (domain.Team, error) {
+	if strings.TrimSpace(teamName) == "" {
+		log.Printf("[Service] UnarchiveTeam: validation error - team name is required")
+		return domain.Team{}, errors.New("team name is required")
+	}
+	team, err := s.repo.UnarchiveTeam(ctx, teamName)
+	if err != nil {
+		log.Printf("[Service] UnarchiveTeam: failed to unarchive team %q: %v", teamName, err)
+		return domain.Team{}, fmt.Errorf("failed to unarchive team: %w", err)
+	}
+	log.Printf("[Service] UnarchiveTeam: unarchived team %q", teamName)
+	return team, nil
+}
+
+// DeleteTeam removes a team no PR was created for; teams with PRs can only
+// be archived.
+func (s *service) DeleteTeam(ctx context.Context, teamName string) error {
+	if strings.TrimSpace(teamName) == "" {
+		log.Printf("[Service] DeleteTeam: validation error - team name is required")
+		return errors.New("team name is required")
+	}
+	if err := s.repo.DeleteTeam(ctx, teamName); err != nil {
+		log.Printf("[Service] DeleteTeam: failed to delete team %q: %v", teamName, err)
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+	log.Printf("[Service] DeleteTeam: deleted team %q", teamName)
+	return nil
+}
+
+// GetTeamTree returns the team with its subteams nested, level by level, down
+// to the leaves of the hierarchy.
+func (s *service) GetTeamTree(ctx context.Context, teamName string) (domain.Team, error) {
+	if strings.TrimSpace(teamName) == "" {
+		log.Printf("[Service] GetTeamTree: validation error - team name is required")
+		return domain.Team{}, errors.New("team name is required")
+	}
+	team, err := s.repo.GetTeam(ctx, teamName)
+	if err != nil {
+		log.Printf("[Service] GetTeamTree: failed to get team %q: %v", teamName, err)
+		return domain.Team{}, fmt.Errorf("failed to get team: %w", err)
+	}
+	if err := s.loadSubteams(ctx, &team, map[string]struct{}{teamName: {}}); err != nil {
+		log.Printf("[Service] GetTeamTree: failed to load subteams of %q: %v", teamName, err)
+		return domain.Team{}, fmt.Errorf("failed to get team: %w", err)
+	}
+	log.Printf("[Service] GetTeamTree: retrieved team %q with %d direct subteams", team.Name, len(team.Subteams))
+	return team, nil
+}
+
+func (s *service) loadSubteams(ctx context.Context, team *domain.Team, seen map[string]struct{}) error {
+	names, err := s.repo.ListSubteams(ctx, team.Name)
+	if err != nil {
+		return err
+	}
+	team.Subteams = []domain.Team{}
+	for _, name := range names {
+		if _, dup := seen[name]; dup {
+			continue
+		}
+		seen[name] = struct{}{}
+		sub, err := s.repo.GetTeam(ctx, name)
+		if errors.Is(err, domain.ErrTeamNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.loadSubteams(ctx, &sub, seen); err != nil {
+			return err
+		}
+		team.Subteams = append(team.Subteams, sub)
+	}
+	return nil
+}
+
+// statsTeams resolves the stats filter: no teams for an empty name, otherwise
+// the team itself and, with subtree, every team below it.
+func (s *service) statsTeams(ctx context.Context, teamName string, subtree bool) ([]string, error) {
+	if teamName == "" {
+		return nil, nil
+	}
+	if _, err := s.repo.GetTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+	names := []string{teamName}
+	if !subtree {
+		return names, nil
+	}
+	seen := map[string]struct{}{teamName: {}}
+	for i := 0; i < len(names); i++ {
+		children, err := s.repo.ListSubteams(ctx, names[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range children {
+			if _, dup := seen[name]; dup {
+				continue
+			}
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// ancestorTeams returns the team's parent, the parent's parent and so on up
+// to the root of the hierarchy.
+func (s *service) ancestorTeams(ctx context.Context, team domain.Team) ([]domain.Team, error) {
+	var ancestors []domain.Team
+	seen := map[string]struct{}{team.Name: {}}
+	name := team.ParentTeam
+	for name != "" {
+		if _, dup := seen[name]; dup {
+			break
+		}
+		seen[name] = struct{}{}
+		parent, err := s.repo.GetTeam(ctx, name)
+		if errors.Is(err, domain.ErrTeamNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ancestors = append(ancestors, parent)
+		name = parent.ParentTeam
+	}
+	return ancestors, nil
+}
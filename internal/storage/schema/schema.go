@@ -29,9 +29,174 @@ var statements = []string{
         reviewer_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
         PRIMARY KEY (pull_request_id, reviewer_id)
     )`,
+	`ALTER TABLE teams ADD COLUMN IF NOT EXISTS required_reviewers INT NOT NULL DEFAULT 2 CHECK (required_reviewers > 0)`,
+	`CREATE TABLE IF NOT EXISTS team_fallbacks (
+        team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+        fallback_team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+        position INT NOT NULL,
+        PRIMARY KEY (team_name, fallback_team_name),
+        CHECK (team_name <> fallback_team_name)
+    )`,
+	`ALTER TABLE pull_request_reviewers ADD COLUMN IF NOT EXISTS fallback_team TEXT NULL`,
+	`CREATE TABLE IF NOT EXISTS ownership_rules (
+        rule_id BIGSERIAL PRIMARY KEY,
+        position INT NOT NULL,
+        pattern TEXT NOT NULL,
+        owner_users TEXT[] NOT NULL DEFAULT '{}',
+        owner_teams TEXT[] NOT NULL DEFAULT '{}'
+    )`,
+	`ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS changed_files TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS required_tags TEXT[] NOT NULL DEFAULT '{}'`,
+	`CREATE TABLE IF NOT EXISTS user_unavailability (
+        window_id BIGSERIAL PRIMARY KEY,
+        user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+        starts_at TIMESTAMPTZ NOT NULL,
+        ends_at TIMESTAMPTZ NOT NULL,
+        reason TEXT NOT NULL DEFAULT '',
+        CHECK (ends_at > starts_at)
+    )`,
+	`ALTER TABLE teams ADD COLUMN IF NOT EXISTS max_open_reviews INT NOT NULL DEFAULT 0 CHECK (max_open_reviews >= 0)`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS max_open_reviews INT NOT NULL DEFAULT 0 CHECK (max_open_reviews >= 0)`,
+	`ALTER TABLE pull_request_reviewers ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'PENDING'
+        CHECK (state IN ('PENDING', 'APPROVED', 'CHANGES_REQUESTED', 'COMMENTED'))`,
+	`ALTER TABLE pull_request_reviewers ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()`,
+	`ALTER TABLE pull_request_reviewers ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMPTZ NULL`,
+	`ALTER TABLE teams ADD COLUMN IF NOT EXISTS required_approvals INT NOT NULL DEFAULT 0 CHECK (required_approvals >= 0)`,
+	`CREATE TABLE IF NOT EXISTS forced_merges (
+        merge_id BIGSERIAL PRIMARY KEY,
+        pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+        forced_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+        approvals INT NOT NULL,
+        required_approvals INT NOT NULL,
+        bypassed TEXT NOT NULL DEFAULT '',
+        reason TEXT NOT NULL DEFAULT ''
+    )`,
+	`DO $$
+    BEGIN
+        IF NOT EXISTS (
+            SELECT 1 FROM pg_constraint
+            WHERE conrelid = 'pull_requests'::regclass AND conname = 'pull_requests_status_check'
+              AND pg_get_constraintdef(oid) LIKE '%DRAFT%'
+        ) THEN
+            ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_status_check;
+            ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_status_check CHECK (status IN ('OPEN', 'MERGED', 'CLOSED', 'DRAFT'));
+        END IF;
+    END $$`,
+	`ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ NULL`,
+	`CREATE TABLE IF NOT EXISTS review_declines (
+        decline_id BIGSERIAL PRIMARY KEY,
+        pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+        user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+        replaced_by TEXT NULL,
+        reason TEXT NOT NULL,
+        declined_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    )`,
+	`ALTER TABLE teams ADD COLUMN IF NOT EXISTS review_sla_hours INT NOT NULL DEFAULT 0 CHECK (review_sla_hours >= 0)`,
+	`ALTER TABLE teams ADD COLUMN IF NOT EXISTS sla_action TEXT NOT NULL DEFAULT 'REASSIGN' CHECK (sla_action IN ('REASSIGN', 'ADD_REVIEWER'))`,
+	`ALTER TABLE pull_request_reviewers ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMPTZ NULL`,
+	`ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS priority TEXT NOT NULL DEFAULT 'NORMAL' CHECK (priority IN ('LOW', 'NORMAL', 'URGENT'))`,
+	`ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS size_lines INT NOT NULL DEFAULT 0 CHECK (size_lines >= 0)`,
+	`ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS load_units INT NOT NULL DEFAULT 1 CHECK (load_units >= 1)`,
+	`DO $$
+    BEGIN
+        IF EXISTS (
+            SELECT 1 FROM information_schema.columns
+            WHERE table_schema = current_schema() AND table_name = 'users'
+              AND column_name = 'team_name' AND is_nullable = 'NO'
+        ) THEN
+            ALTER TABLE users ALTER COLUMN team_name DROP NOT NULL;
+        END IF;
+    END $$`,
+	`DO $$
+    BEGIN
+        IF NOT EXISTS (
+            SELECT 1 FROM pg_constraint
+            WHERE conrelid = 'users'::regclass AND conname = 'users_team_name_fkey' AND confupdtype = 'c'
+        ) THEN
+            ALTER TABLE users DROP CONSTRAINT IF EXISTS users_team_name_fkey;
+            ALTER TABLE users ADD CONSTRAINT users_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE RESTRICT ON UPDATE CASCADE;
+        END IF;
+    END $$`,
+	`DO $$
+    BEGIN
+        IF NOT EXISTS (
+            SELECT 1 FROM pg_constraint
+            WHERE conrelid = 'team_fallbacks'::regclass AND conname = 'team_fallbacks_team_name_fkey' AND confupdtype = 'c'
+        ) THEN
+            ALTER TABLE team_fallbacks DROP CONSTRAINT IF EXISTS team_fallbacks_team_name_fkey;
+            ALTER TABLE team_fallbacks ADD CONSTRAINT team_fallbacks_team_name_fkey FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE ON UPDATE CASCADE;
+        END IF;
+    END $$`,
+	`DO $$
+    BEGIN
+        IF NOT EXISTS (
+            SELECT 1 FROM pg_constraint
+            WHERE conrelid = 'team_fallbacks'::regclass AND conname = 'team_fallbacks_fallback_team_name_fkey' AND confupdtype = 'c'
+        ) THEN
+            ALTER TABLE team_fallbacks DROP CONSTRAINT IF EXISTS team_fallbacks_fallback_team_name_fkey;
+            ALTER TABLE team_fallbacks ADD CONSTRAINT team_fallbacks_fallback_team_name_fkey FOREIGN KEY (fallback_team_name) REFERENCES teams(team_name) ON DELETE CASCADE ON UPDATE CASCADE;
+        END IF;
+    END $$`,
+	`CREATE TABLE IF NOT EXISTS team_moves (
+        move_id BIGSERIAL PRIMARY KEY,
+        user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+        from_team TEXT NULL,
+        to_team TEXT NULL,
+        reassigned INT NOT NULL DEFAULT 0,
+        moved_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    )`,
+	`ALTER TABLE teams ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ NULL`,
+	`DO $$
+    BEGIN
+        IF to_regclass('team_members') IS NULL THEN
+            CREATE TABLE team_members (
+                team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE ON UPDATE CASCADE,
+                user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+                joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                PRIMARY KEY (team_name, user_id)
+            );
+            INSERT INTO team_members (team_name, user_id)
+                SELECT team_name, user_id FROM users WHERE team_name IS NOT NULL;
+        END IF;
+    END $$`,
+	`DO $$
+    BEGIN
+        IF NOT EXISTS (
+            SELECT 1 FROM information_schema.columns
+            WHERE table_schema = current_schema() AND table_name = 'pull_requests' AND column_name = 'team_name'
+        ) THEN
+            ALTER TABLE pull_requests ADD COLUMN team_name TEXT NULL REFERENCES teams(team_name) ON UPDATE CASCADE;
+            UPDATE pull_requests pr SET team_name = u.team_name
+                FROM users u
+                WHERE u.user_id = pr.author_id AND u.team_name IS NOT NULL;
+        END IF;
+    END $$`,
+	`ALTER TABLE teams ADD COLUMN IF NOT EXISTS parent_team TEXT NULL`,
+	`DO $$
+    BEGIN
+        IF NOT EXISTS (
+            SELECT 1 FROM pg_constraint
+            WHERE conrelid = 'teams'::regclass AND conname = 'teams_parent_team_fkey'
+        ) THEN
+            ALTER TABLE teams ADD CONSTRAINT teams_parent_team_fkey FOREIGN KEY (parent_team) REFERENCES teams(team_name) ON DELETE SET NULL ON UPDATE CASCADE;
+        END IF;
+        IF NOT EXISTS (
+            SELECT 1 FROM pg_constraint
+            WHERE conrelid = 'teams'::regclass AND conname = 'teams_parent_team_check'
+        ) THEN
+            ALTER TABLE teams ADD CONSTRAINT teams_parent_team_check CHECK (parent_team <> team_name);
+        END IF;
+    END $$`,
 	`CREATE INDEX IF NOT EXISTS idx_users_team ON users(team_name)`,
+	`CREATE INDEX IF NOT EXISTS idx_teams_parent ON teams(parent_team)`,
+	`CREATE INDEX IF NOT EXISTS idx_team_members_user ON team_members(user_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_pull_requests_team ON pull_requests(team_name)`,
 	`CREATE INDEX IF NOT EXISTS idx_pull_requests_author ON pull_requests(author_id)`,
 	`CREATE INDEX IF NOT EXISTS idx_reviewers_user ON pull_request_reviewers(reviewer_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_unavailability_user ON user_unavailability(user_id, ends_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_declines_user ON review_declines(user_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_team_moves_user ON team_moves(user_id, moved_at)`,
 }
 
 func Ensure(ctx context.Context, pool *pgxpool.Pool) error {
@@ -13,6 +13,7 @@ import (
 
 	"github.com/dangy/pr-reviewer-assignment-service/internal/domain"
 	"github.com/dangy/pr-reviewer-assignment-service/internal/repository"
+	"github.com/dangy/pr-reviewer-assignment-service/internal/service"
 	"github.com/dangy/pr-reviewer-assignment-service/internal/storage/schema"
 )
 
@@ -57,8 +58,8 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
-// setupTest создает новый репозиторий и очищает таблицы перед каждым тестом
-func setupTest(t *testing.T) (*repository.Repository, func()) {
+// setupTest создает сервис поверх нового репозитория и очищает таблицы перед каждым тестом
+func setupTest(t *testing.T) (service.Service, func()) {
 	ctx := context.Background()
 
 	// Очистить таблицы в правильном порядке (из-за foreign keys)
@@ -67,20 +68,24 @@ func setupTest(t *testing.T) (*repository.Repository, func()) {
 		TRUNCATE TABLE pull_requests CASCADE;
 		TRUNCATE TABLE users CASCADE;
 		TRUNCATE TABLE teams CASCADE;
+		TRUNCATE TABLE ownership_rules;
 	`)
 	require.NoError(t, err, "Failed to truncate tables")
 
-	repo := repository.New(testDBPool)
+	strategies, err := service.NewStrategies(service.StrategyLeastLoaded, nil)
+	require.NoError(t, err)
+
+	svc := service.New(repository.New(testDBPool), strategies, domain.DefaultWorkday)
 
 	cleanup := func() {
 		// Дополнительная очистка если нужна
 	}
 
-	return repo, cleanup
+	return svc, cleanup
 }
 
 func TestCreateTeam(t *testing.T) {
-	repo, cleanup := setupTest(t)
+	svc, cleanup := setupTest(t)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -94,7 +99,7 @@ func TestCreateTeam(t *testing.T) {
 			},
 		}
 
-		created, err := repo.CreateTeam(ctx, team)
+		created, err := svc.CreateTeam(ctx, team, false)
 		require.NoError(t, err)
 		assert.Equal(t, "backend", created.Name)
 		assert.Len(t, created.Members, 2)
@@ -113,11 +118,11 @@ func TestCreateTeam(t *testing.T) {
 			},
 		}
 
-		_, err := repo.CreateTeam(ctx, team)
+		_, err := svc.CreateTeam(ctx, team, false)
 		require.NoError(t, err)
 
 		// Повторная попытка создания должна вернуть ошибку
-		_, err = repo.CreateTeam(ctx, team)
+		_, err = svc.CreateTeam(ctx, team, false)
 		assert.ErrorIs(t, err, domain.ErrTeamExists)
 	})
 
@@ -129,7 +134,7 @@ func TestCreateTeam(t *testing.T) {
 				{ID: "u4", Username: "David", IsActive: true},
 			},
 		}
-		_, err := repo.CreateTeam(ctx, team1)
+		_, err := svc.CreateTeam(ctx, team1, false)
 		require.NoError(t, err)
 
 		// Создать вторую команду с тем же пользователем (обновление)
@@ -139,18 +144,31 @@ func TestCreateTeam(t *testing.T) {
 				{ID: "u4", Username: "David Updated", IsActive: false},
 			},
 		}
-		created, err := repo.CreateTeam(ctx, team2)
+		// Без явного разрешения пользователь не переносится, команда не создается
+		_, err = svc.CreateTeam(ctx, team2, false)
+		assert.ErrorIs(t, err, domain.ErrUserInOtherTeam)
+		_, err = svc.GetTeam(ctx, "team2")
+		assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+
+		created, err := svc.CreateTeam(ctx, team2, true)
 		require.NoError(t, err)
 
 		// Проверить что пользователь обновился
 		assert.Equal(t, "David Updated", created.Members[0].Username)
 		assert.Equal(t, "team2", created.Members[0].TeamName)
 		assert.False(t, created.Members[0].IsActive)
+
+		// Перенос записан в историю
+		moves, err := svc.ListTeamMoves(ctx, "u4")
+		require.NoError(t, err)
+		require.Len(t, moves, 1)
+		assert.Equal(t, "team1", moves[0].FromTeam)
+		assert.Equal(t, "team2", moves[0].ToTeam)
 	})
 }
 
 func TestGetTeam(t *testing.T) {
-	repo, cleanup := setupTest(t)
+	svc, cleanup := setupTest(t)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -164,11 +182,11 @@ func TestGetTeam(t *testing.T) {
 				{ID: "u2", Username: "Bob", IsActive: false},
 			},
 		}
-		_, err := repo.CreateTeam(ctx, team)
+		_, err := svc.CreateTeam(ctx, team, false)
 		require.NoError(t, err)
 
 		// Получить команду
-		found, err := repo.GetTeam(ctx, "backend")
+		found, err := svc.GetTeam(ctx, "backend")
 		require.NoError(t, err)
 		assert.Equal(t, "backend", found.Name)
 		assert.Len(t, found.Members, 2)
@@ -179,13 +197,13 @@ func TestGetTeam(t *testing.T) {
 	})
 
 	t.Run("несуществующая команда возвращает ошибку", func(t *testing.T) {
-		_, err := repo.GetTeam(ctx, "nonexistent")
+		_, err := svc.GetTeam(ctx, "nonexistent")
 		assert.ErrorIs(t, err, domain.ErrTeamNotFound)
 	})
 }
 
 func TestSetUserActivity(t *testing.T) {
-	repo, cleanup := setupTest(t)
+	svc, cleanup := setupTest(t)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -197,11 +215,11 @@ func TestSetUserActivity(t *testing.T) {
 			{ID: "u1", Username: "Alice", IsActive: true},
 		},
 	}
-	_, err := repo.CreateTeam(ctx, team)
+	_, err := svc.CreateTeam(ctx, team, false)
 	require.NoError(t, err)
 
 	t.Run("деактивация активного пользователя", func(t *testing.T) {
-		user, err := repo.SetUserActivity(ctx, "u1", false)
+		user, err := svc.SetUserActivity(ctx, "u1", false)
 		require.NoError(t, err)
 		assert.False(t, user.IsActive)
 		assert.Equal(t, "Alice", user.Username)
@@ -209,19 +227,19 @@ func TestSetUserActivity(t *testing.T) {
 	})
 
 	t.Run("активация неактивного пользователя", func(t *testing.T) {
-		user, err := repo.SetUserActivity(ctx, "u1", true)
+		user, err := svc.SetUserActivity(ctx, "u1", true)
 		require.NoError(t, err)
 		assert.True(t, user.IsActive)
 	})
 
 	t.Run("несуществующий пользователь возвращает ошибку", func(t *testing.T) {
-		_, err := repo.SetUserActivity(ctx, "nonexistent", true)
+		_, err := svc.SetUserActivity(ctx, "nonexistent", true)
 		assert.ErrorIs(t, err, domain.ErrUserNotFound)
 	})
 }
 
 func TestCreatePullRequest(t *testing.T) {
-	repo, cleanup := setupTest(t)
+	svc, cleanup := setupTest(t)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -236,11 +254,11 @@ func TestCreatePullRequest(t *testing.T) {
 			{ID: "u4", Username: "David", IsActive: false}, // Неактивный
 		},
 	}
-	_, err := repo.CreateTeam(ctx, team)
+	_, err := svc.CreateTeam(ctx, team, false)
 	require.NoError(t, err)
 
 	t.Run("успешное создание PR с назначением ревьюеров", func(t *testing.T) {
-		pr, err := repo.CreatePullRequest(ctx, "pr1", "Add feature", "u1")
+		pr, err := svc.CreatePullRequest(ctx, "pr1", "Add feature", "u1", domain.PullRequestOptions{})
 		require.NoError(t, err)
 
 		assert.Equal(t, "pr1", pr.ID)
@@ -261,7 +279,7 @@ func TestCreatePullRequest(t *testing.T) {
 	})
 
 	t.Run("неактивные пользователи не назначаются", func(t *testing.T) {
-		pr, err := repo.CreatePullRequest(ctx, "pr2", "Fix bug", "u1")
+		pr, err := svc.CreatePullRequest(ctx, "pr2", "Fix bug", "u1", domain.PullRequestOptions{})
 		require.NoError(t, err)
 
 		// u4 не должен быть назначен (is_active = false)
@@ -278,10 +296,10 @@ func TestCreatePullRequest(t *testing.T) {
 				{ID: "u5", Username: "Solo", IsActive: true},
 			},
 		}
-		_, err := repo.CreateTeam(ctx, soloTeam)
+		_, err := svc.CreateTeam(ctx, soloTeam, false)
 		require.NoError(t, err)
 
-		pr, err := repo.CreatePullRequest(ctx, "pr3", "Solo PR", "u5")
+		pr, err := svc.CreatePullRequest(ctx, "pr3", "Solo PR", "u5", domain.PullRequestOptions{})
 		require.NoError(t, err)
 
 		// Ревьюеров не должно быть (некого назначить)
@@ -289,18 +307,18 @@ func TestCreatePullRequest(t *testing.T) {
 	})
 
 	t.Run("попытка создать дубликат PR", func(t *testing.T) {
-		_, err := repo.CreatePullRequest(ctx, "pr1", "Duplicate", "u1")
+		_, err := svc.CreatePullRequest(ctx, "pr1", "Duplicate", "u1", domain.PullRequestOptions{})
 		assert.ErrorIs(t, err, domain.ErrPRExists)
 	})
 
 	t.Run("несуществующий автор возвращает ошибку", func(t *testing.T) {
-		_, err := repo.CreatePullRequest(ctx, "pr4", "Invalid", "nonexistent")
+		_, err := svc.CreatePullRequest(ctx, "pr4", "Invalid", "nonexistent", domain.PullRequestOptions{})
 		assert.ErrorIs(t, err, domain.ErrUserNotFound)
 	})
 }
 
 func TestGetPullRequest(t *testing.T) {
-	repo, cleanup := setupTest(t)
+	svc, cleanup := setupTest(t)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -313,14 +331,14 @@ func TestGetPullRequest(t *testing.T) {
 			{ID: "u2", Username: "Bob", IsActive: true},
 		},
 	}
-	_, err := repo.CreateTeam(ctx, team)
+	_, err := svc.CreateTeam(ctx, team, false)
 	require.NoError(t, err)
 
-	pr, err := repo.CreatePullRequest(ctx, "pr1", "Test PR", "u1")
+	pr, err := svc.CreatePullRequest(ctx, "pr1", "Test PR", "u1", domain.PullRequestOptions{})
 	require.NoError(t, err)
 
 	t.Run("получение существующего PR", func(t *testing.T) {
-		found, err := repo.GetPullRequest(ctx, "pr1")
+		found, err := svc.GetPullRequest(ctx, "pr1")
 		require.NoError(t, err)
 		assert.Equal(t, pr.ID, found.ID)
 		assert.Equal(t, pr.Name, found.Name)
@@ -328,13 +346,13 @@ func TestGetPullRequest(t *testing.T) {
 	})
 
 	t.Run("несуществующий PR возвращает ошибку", func(t *testing.T) {
-		_, err := repo.GetPullRequest(ctx, "nonexistent")
+		_, err := svc.GetPullRequest(ctx, "nonexistent")
 		assert.ErrorIs(t, err, domain.ErrPRNotFound)
 	})
 }
 
 func TestMergePullRequest(t *testing.T) {
-	repo, cleanup := setupTest(t)
+	svc, cleanup := setupTest(t)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -347,15 +365,15 @@ func TestMergePullRequest(t *testing.T) {
 			{ID: "u2", Username: "Bob", IsActive: true},
 		},
 	}
-	_, err := repo.CreateTeam(ctx, team)
+	_, err := svc.CreateTeam(ctx, team, false)
 	require.NoError(t, err)
 
-	pr, err := repo.CreatePullRequest(ctx, "pr1", "Test PR", "u1")
+	pr, err := svc.CreatePullRequest(ctx, "pr1", "Test PR", "u1", domain.PullRequestOptions{})
 	require.NoError(t, err)
 	require.Equal(t, domain.PullRequestStatusOpen, pr.Status)
 
 	t.Run("успешный merge PR", func(t *testing.T) {
-		merged, err := repo.MergePullRequest(ctx, "pr1")
+		merged, err := svc.MergePullRequest(ctx, "pr1", domain.MergeOptions{})
 		require.NoError(t, err)
 
 		assert.Equal(t, domain.PullRequestStatusMerged, merged.Status)
@@ -364,11 +382,11 @@ func TestMergePullRequest(t *testing.T) {
 	})
 
 	t.Run("повторный merge возвращает тот же PR (идемпотентность)", func(t *testing.T) {
-		firstMerge, err := repo.MergePullRequest(ctx, "pr1")
+		firstMerge, err := svc.MergePullRequest(ctx, "pr1", domain.MergeOptions{})
 		require.NoError(t, err)
 		firstTime := firstMerge.MergedAt
 
-		secondMerge, err := repo.MergePullRequest(ctx, "pr1")
+		secondMerge, err := svc.MergePullRequest(ctx, "pr1", domain.MergeOptions{})
 		require.NoError(t, err)
 
 		assert.Equal(t, domain.PullRequestStatusMerged, secondMerge.Status)
@@ -377,13 +395,13 @@ func TestMergePullRequest(t *testing.T) {
 	})
 
 	t.Run("несуществующий PR возвращает ошибку", func(t *testing.T) {
-		_, err := repo.MergePullRequest(ctx, "nonexistent")
+		_, err := svc.MergePullRequest(ctx, "nonexistent", domain.MergeOptions{})
 		assert.ErrorIs(t, err, domain.ErrPRNotFound)
 	})
 }
 
 func TestReassignReviewer(t *testing.T) {
-	repo, cleanup := setupTest(t)
+	svc, cleanup := setupTest(t)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -398,17 +416,17 @@ func TestReassignReviewer(t *testing.T) {
 			{ID: "u4", Username: "David", IsActive: true},
 		},
 	}
-	_, err := repo.CreateTeam(ctx, team)
+	_, err := svc.CreateTeam(ctx, team, false)
 	require.NoError(t, err)
 
-	pr, err := repo.CreatePullRequest(ctx, "pr1", "Test PR", "u1")
+	pr, err := svc.CreatePullRequest(ctx, "pr1", "Test PR", "u1", domain.PullRequestOptions{})
 	require.NoError(t, err)
 	require.Greater(t, len(pr.AssignedReviewers), 0, "PR should have reviewers")
 
 	t.Run("успешное переназначение ревьюера", func(t *testing.T) {
 		oldReviewer := pr.AssignedReviewers[0]
 
-		updated, newReviewer, err := repo.ReassignReviewer(ctx, "pr1", oldReviewer)
+		updated, newReviewer, err := svc.ReassignReviewer(ctx, "pr1", oldReviewer, "")
 		require.NoError(t, err)
 		assert.NotEmpty(t, newReviewer)
 
@@ -436,11 +454,11 @@ func TestReassignReviewer(t *testing.T) {
 				{ID: "test-reviewer-inactive", Username: "TestReviewerInactive", IsActive: false},
 			},
 		}
-		_, err := repo.CreateTeam(ctx, testTeam)
+		_, err := svc.CreateTeam(ctx, testTeam, false)
 		require.NoError(t, err)
 
 		// Создать PR - будет назначен только test-reviewer-active
-		testPR, err := repo.CreatePullRequest(ctx, "test-pr-not-assigned", "Test", "test-author")
+		testPR, err := svc.CreatePullRequest(ctx, "test-pr-not-assigned", "Test", "test-author", domain.PullRequestOptions{})
 		require.NoError(t, err)
 
 		// Убедиться что назначен только активный ревьюер
@@ -448,20 +466,20 @@ func TestReassignReviewer(t *testing.T) {
 		require.Equal(t, "test-reviewer-active", testPR.AssignedReviewers[0])
 
 		// Попытка переназначить неактивного пользователя (не назначен)
-		_, _, err = repo.ReassignReviewer(ctx, "test-pr-not-assigned", "test-reviewer-inactive")
+		_, _, err = svc.ReassignReviewer(ctx, "test-pr-not-assigned", "test-reviewer-inactive", "")
 		assert.ErrorIs(t, err, domain.ErrNotAssigned)
 	})
 
 	t.Run("переназначение на смерженном PR", func(t *testing.T) {
 		// Создать и смержить PR
-		pr2, err := repo.CreatePullRequest(ctx, "pr2", "Another PR", "u1")
+		pr2, err := svc.CreatePullRequest(ctx, "pr2", "Another PR", "u1", domain.PullRequestOptions{})
 		require.NoError(t, err)
 
-		_, err = repo.MergePullRequest(ctx, "pr2")
+		_, err = svc.MergePullRequest(ctx, "pr2", domain.MergeOptions{})
 		require.NoError(t, err)
 
 		if len(pr2.AssignedReviewers) > 0 {
-			_, _, err = repo.ReassignReviewer(ctx, "pr2", pr2.AssignedReviewers[0])
+			_, _, err = svc.ReassignReviewer(ctx, "pr2", pr2.AssignedReviewers[0], "")
 			assert.ErrorIs(t, err, domain.ErrPRMerged)
 		}
 	})
@@ -475,27 +493,27 @@ func TestReassignReviewer(t *testing.T) {
 				{ID: "u6", Username: "Frank", IsActive: true},
 			},
 		}
-		_, err := repo.CreateTeam(ctx, smallTeam)
+		_, err := svc.CreateTeam(ctx, smallTeam, false)
 		require.NoError(t, err)
 
-		pr3, err := repo.CreatePullRequest(ctx, "pr3", "Small team PR", "u5")
+		pr3, err := svc.CreatePullRequest(ctx, "pr3", "Small team PR", "u5", domain.PullRequestOptions{})
 		require.NoError(t, err)
 
 		// Если u6 назначен, попытка переназначить должна вернуть NO_CANDIDATE
 		if len(pr3.AssignedReviewers) > 0 && pr3.AssignedReviewers[0] == "u6" {
-			_, _, err = repo.ReassignReviewer(ctx, "pr3", "u6")
+			_, _, err = svc.ReassignReviewer(ctx, "pr3", "u6", "")
 			assert.ErrorIs(t, err, domain.ErrNoCandidate)
 		}
 	})
 
 	t.Run("несуществующий PR возвращает ошибку", func(t *testing.T) {
-		_, _, err := repo.ReassignReviewer(ctx, "nonexistent", "u2")
+		_, _, err := svc.ReassignReviewer(ctx, "nonexistent", "u2", "")
 		assert.ErrorIs(t, err, domain.ErrPRNotFound)
 	})
 }
 
 func TestListReviewerPullRequests(t *testing.T) {
-	repo, cleanup := setupTest(t)
+	svc, cleanup := setupTest(t)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -509,19 +527,19 @@ func TestListReviewerPullRequests(t *testing.T) {
 			{ID: "u3", Username: "Charlie", IsActive: true},
 		},
 	}
-	_, err := repo.CreateTeam(ctx, team)
+	_, err := svc.CreateTeam(ctx, team, false)
 	require.NoError(t, err)
 
 	// Создать несколько PR от u1
-	_, err = repo.CreatePullRequest(ctx, "pr1", "PR 1", "u1")
+	_, err = svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
 	require.NoError(t, err)
-	_, err = repo.CreatePullRequest(ctx, "pr2", "PR 2", "u1")
+	_, err = svc.CreatePullRequest(ctx, "pr2", "PR 2", "u1", domain.PullRequestOptions{})
 	require.NoError(t, err)
-	_, err = repo.CreatePullRequest(ctx, "pr3", "PR 3", "u1")
+	_, err = svc.CreatePullRequest(ctx, "pr3", "PR 3", "u1", domain.PullRequestOptions{})
 	require.NoError(t, err)
 
 	t.Run("получение списка PR для ревьюера", func(t *testing.T) {
-		prs, err := repo.ListReviewerPullRequests(ctx, "u2")
+		prs, err := svc.ListReviewerPullRequests(ctx, "u2")
 		require.NoError(t, err)
 
 		// u2 должен быть назначен хотя бы на один PR (random selection)
@@ -543,17 +561,17 @@ func TestListReviewerPullRequests(t *testing.T) {
 				{ID: "u10", Username: "NewUser", IsActive: true},
 			},
 		}
-		_, err := repo.CreateTeam(ctx, newTeam)
+		_, err := svc.CreateTeam(ctx, newTeam, false)
 		require.NoError(t, err)
 
-		prs, err := repo.ListReviewerPullRequests(ctx, "u10")
+		prs, err := svc.ListReviewerPullRequests(ctx, "u10")
 		require.NoError(t, err)
 		assert.Empty(t, prs)
 	})
 }
 
 func TestGetReviewerStats(t *testing.T) {
-	repo, cleanup := setupTest(t)
+	svc, cleanup := setupTest(t)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -567,17 +585,17 @@ func TestGetReviewerStats(t *testing.T) {
 			{ID: "u3", Username: "Charlie", IsActive: true},
 		},
 	}
-	_, err := repo.CreateTeam(ctx, team)
+	_, err := svc.CreateTeam(ctx, team, false)
 	require.NoError(t, err)
 
 	// Создать несколько PR для статистики
 	for i := 1; i <= 5; i++ {
-		_, err = repo.CreatePullRequest(ctx, fmt.Sprintf("pr%d", i), fmt.Sprintf("PR %d", i), "u1")
+		_, err = svc.CreatePullRequest(ctx, fmt.Sprintf("pr%d", i), fmt.Sprintf("PR %d", i), "u1", domain.PullRequestOptions{})
 		require.NoError(t, err)
 	}
 
 	t.Run("получение статистики по ревьюерам", func(t *testing.T) {
-		stats, err := repo.GetReviewerStats(ctx)
+		stats, err := svc.GetReviewerStats(ctx, "", false)
 		require.NoError(t, err)
 
 		assert.Len(t, stats, 3, "Should have stats for all 3 users")
@@ -604,7 +622,7 @@ func TestGetReviewerStats(t *testing.T) {
 }
 
 func TestGetPRStats(t *testing.T) {
-	repo, cleanup := setupTest(t)
+	svc, cleanup := setupTest(t)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -617,23 +635,23 @@ func TestGetPRStats(t *testing.T) {
 			{ID: "u2", Username: "Bob", IsActive: true},
 		},
 	}
-	_, err := repo.CreateTeam(ctx, team)
+	_, err := svc.CreateTeam(ctx, team, false)
 	require.NoError(t, err)
 
 	// Создать несколько PR
-	pr1, err := repo.CreatePullRequest(ctx, "pr1", "PR 1", "u1")
+	pr1, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
 	require.NoError(t, err)
-	_, err = repo.CreatePullRequest(ctx, "pr2", "PR 2", "u1")
+	_, err = svc.CreatePullRequest(ctx, "pr2", "PR 2", "u1", domain.PullRequestOptions{})
 	require.NoError(t, err)
-	_, err = repo.CreatePullRequest(ctx, "pr3", "PR 3", "u1")
+	_, err = svc.CreatePullRequest(ctx, "pr3", "PR 3", "u1", domain.PullRequestOptions{})
 	require.NoError(t, err)
 
 	// Смержить один PR
-	_, err = repo.MergePullRequest(ctx, pr1.ID)
+	_, err = svc.MergePullRequest(ctx, pr1.ID, domain.MergeOptions{})
 	require.NoError(t, err)
 
 	t.Run("получение статистики по PR", func(t *testing.T) {
-		stats, err := repo.GetPRStats(ctx)
+		stats, err := svc.GetPRStats(ctx, "", false)
 		require.NoError(t, err)
 
 		assert.Equal(t, 3, stats.TotalPRs)
@@ -650,7 +668,7 @@ func TestGetPRStats(t *testing.T) {
 		_, err := testDBPool.Exec(ctx, "TRUNCATE TABLE pull_request_reviewers, pull_requests, users, teams CASCADE")
 		require.NoError(t, err)
 
-		stats, err := repo.GetPRStats(ctx)
+		stats, err := svc.GetPRStats(ctx, "", false)
 		require.NoError(t, err)
 
 		assert.Equal(t, 0, stats.TotalPRs)
@@ -660,3 +678,1951 @@ func TestGetPRStats(t *testing.T) {
 		assert.Equal(t, 0, stats.PRsWithoutReviewers)
 	})
 }
+
+func TestLeastLoadedSelection(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	team := domain.Team{
+		Name: "backend",
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true},
+			{ID: "u4", Username: "David", IsActive: true},
+		},
+	}
+	_, err := svc.CreateTeam(ctx, team, false)
+	require.NoError(t, err)
+
+	t.Run("нагрузка распределяется равномерно", func(t *testing.T) {
+		// 3 PR * 2 ревьюера = 6 назначений на 3 кандидатов
+		for i := 1; i <= 3; i++ {
+			_, err := svc.CreatePullRequest(ctx, fmt.Sprintf("pr%d", i), fmt.Sprintf("PR %d", i), "u1", domain.PullRequestOptions{})
+			require.NoError(t, err)
+		}
+
+		for _, userID := range []string{"u2", "u3", "u4"} {
+			prs, err := svc.ListReviewerPullRequests(ctx, userID)
+			require.NoError(t, err)
+			assert.Len(t, prs, 2, "user %s", userID)
+		}
+	})
+
+	t.Run("смерженные PR не учитываются в нагрузке", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr4", "PR 4", "u2", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		require.Len(t, pr.AssignedReviewers, 2)
+
+		// Смержить первые три PR — их назначения больше не нагрузка
+		for i := 1; i <= 3; i++ {
+			_, err := svc.MergePullRequest(ctx, fmt.Sprintf("pr%d", i), domain.MergeOptions{})
+			require.NoError(t, err)
+		}
+
+		created, err := svc.CreatePullRequest(ctx, "pr5", "PR 5", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		// Ревьюеры pr4 загружены одним OPEN PR, остальные свободны
+		for _, reviewerID := range created.AssignedReviewers {
+			if reviewerID == "u2" {
+				continue
+			}
+			assert.NotContains(t, pr.AssignedReviewers, reviewerID)
+		}
+	})
+}
+
+func TestRequiredReviewers(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	team := domain.Team{
+		Name:              "security",
+		RequiredReviewers: 3,
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true},
+			{ID: "u4", Username: "David", IsActive: true},
+		},
+	}
+	created, err := svc.CreateTeam(ctx, team, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, created.RequiredReviewers)
+
+	t.Run("назначается заданное командой число ревьюеров", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Len(t, pr.AssignedReviewers, 3)
+
+		found, err := svc.GetPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.Equal(t, 3, found.RequiredReviewers)
+		assert.False(t, found.NeedMoreReviewers())
+	})
+
+	t.Run("изменение настройки команды", func(t *testing.T) {
+		one := 1
+		updated, err := svc.UpdateTeam(ctx, "security", domain.TeamUpdate{RequiredReviewers: &one})
+		require.NoError(t, err)
+		assert.Equal(t, 1, updated.RequiredReviewers)
+
+		pr, err := svc.CreatePullRequest(ctx, "pr2", "PR 2", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Len(t, pr.AssignedReviewers, 1)
+	})
+
+	t.Run("команда по умолчанию требует двух ревьюеров", func(t *testing.T) {
+		created, err := svc.CreateTeam(ctx, domain.Team{
+			Name:    "backend",
+			Members: []domain.User{{ID: "u5", Username: "Eve", IsActive: true}},
+		}, false)
+		require.NoError(t, err)
+		assert.Equal(t, domain.DefaultRequiredReviewers, created.RequiredReviewers)
+	})
+
+	t.Run("несуществующая команда возвращает ошибку", func(t *testing.T) {
+		two := 2
+		_, err := svc.UpdateTeam(ctx, "nonexistent", domain.TeamUpdate{RequiredReviewers: &two})
+		assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+	})
+}
+
+func TestBackfillReviewers(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	team := domain.Team{
+		Name: "backend",
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: false},
+		},
+	}
+	_, err := svc.CreateTeam(ctx, team, false)
+	require.NoError(t, err)
+
+	pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+	require.True(t, pr.NeedMoreReviewers())
+
+	t.Run("без новых кандидатов ничего не меняется", func(t *testing.T) {
+		results, err := svc.BackfillReviewers(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("вернувшийся участник назначается на PR", func(t *testing.T) {
+		_, err := svc.SetUserActivity(ctx, "u3", true)
+		require.NoError(t, err)
+
+		results, err := svc.BackfillReviewers(ctx)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "pr1", results[0].PullRequest.ID)
+		assert.Equal(t, []string{"u3"}, results[0].AddedReviewers)
+		assert.ElementsMatch(t, []string{"u2", "u3"}, results[0].PullRequest.AssignedReviewers)
+		assert.False(t, results[0].PullRequest.NeedMoreReviewers())
+	})
+
+	t.Run("смерженные PR не дополняются", func(t *testing.T) {
+		_, err := svc.SetUserActivity(ctx, "u3", false)
+		require.NoError(t, err)
+		pr2, err := svc.CreatePullRequest(ctx, "pr2", "PR 2", "u2", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"u1"}, pr2.AssignedReviewers)
+		_, err = svc.MergePullRequest(ctx, "pr2", domain.MergeOptions{})
+		require.NoError(t, err)
+		_, err = svc.SetUserActivity(ctx, "u3", true)
+		require.NoError(t, err)
+
+		results, err := svc.BackfillReviewers(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestDeactivateWithReassignment(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	team := domain.Team{
+		Name: "backend",
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true},
+			{ID: "u4", Username: "David", IsActive: true},
+		},
+	}
+	_, err := svc.CreateTeam(ctx, team, false)
+	require.NoError(t, err)
+
+	t.Run("открытые ревью переходят к другим участникам", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		leaving := pr.AssignedReviewers[0]
+
+		user, moves, err := svc.DeactivateUser(ctx, leaving)
+		require.NoError(t, err)
+		assert.False(t, user.IsActive)
+		require.Len(t, moves, 1)
+		assert.Equal(t, "pr1", moves[0].PullRequestID)
+		assert.Equal(t, leaving, moves[0].OldReviewerID)
+		assert.NotEmpty(t, moves[0].NewReviewerID)
+		assert.NotEqual(t, "u1", moves[0].NewReviewerID)
+
+		updated, err := svc.GetPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.NotContains(t, updated.AssignedReviewers, leaving)
+		assert.Contains(t, updated.AssignedReviewers, moves[0].NewReviewerID)
+		assert.Len(t, updated.AssignedReviewers, 2)
+	})
+
+	t.Run("без кандидатов указывается причина", func(t *testing.T) {
+		_, err := testDBPool.Exec(ctx, "UPDATE users SET is_active = TRUE")
+		require.NoError(t, err)
+
+		pr, err := svc.CreatePullRequest(ctx, "pr2", "PR 2", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		require.Len(t, pr.AssignedReviewers, 2)
+
+		users, moves, err := svc.DeactivateTeamMembers(ctx, "backend", []string{"u2", "u3", "u4"}, true)
+		require.NoError(t, err)
+		assert.Len(t, users, 3)
+		for _, move := range moves {
+			assert.Empty(t, move.NewReviewerID)
+			assert.Equal(t, domain.ErrNoCandidate.Error(), move.Reason)
+		}
+
+		updated, err := svc.GetPullRequest(ctx, "pr2")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, pr.AssignedReviewers, updated.AssignedReviewers)
+	})
+
+	t.Run("пользователь из другой команды отклоняется", func(t *testing.T) {
+		_, _, err := svc.DeactivateTeamMembers(ctx, "backend", []string{"nonexistent"}, true)
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	})
+}
+
+func TestFallbackTeams(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := svc.CreateTeam(ctx, domain.Team{
+		Name: "platform",
+		Members: []domain.User{
+			{ID: "p1", Username: "Pat", IsActive: true},
+			{ID: "p2", Username: "Quinn", IsActive: true},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	squad, err := svc.CreateTeam(ctx, domain.Team{
+		Name:          "squad",
+		FallbackTeams: []string{"platform"},
+		Members: []domain.User{
+			{ID: "s1", Username: "Sam", IsActive: true},
+			{ID: "s2", Username: "Taylor", IsActive: true},
+		},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform"}, squad.FallbackTeams)
+
+	t.Run("недостающий ревьюер берется из резервной команды", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "s1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		require.Len(t, pr.AssignedReviewers, 2)
+		assert.Contains(t, pr.AssignedReviewers, "s2")
+		require.Len(t, pr.FallbackReviewers, 1)
+		for reviewerID, team := range pr.FallbackReviewers {
+			assert.Contains(t, []string{"p1", "p2"}, reviewerID)
+			assert.Equal(t, "platform", team)
+		}
+
+		found, err := svc.GetPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.Equal(t, pr.FallbackReviewers, found.FallbackReviewers)
+	})
+
+	t.Run("резервная команда не используется, если хватает своих", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr2", "PR 2", "p1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"p2"}, pr.AssignedReviewers)
+		assert.Empty(t, pr.FallbackReviewers)
+	})
+
+	t.Run("переназначение берет кандидата из резервной команды", func(t *testing.T) {
+		_, replacement, err := svc.ReassignReviewer(ctx, "pr1", "s2", "")
+		require.NoError(t, err)
+		assert.Contains(t, []string{"p1", "p2"}, replacement)
+
+		found, err := svc.GetPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.Len(t, found.FallbackReviewers, 2)
+	})
+
+	t.Run("несуществующая резервная команда", func(t *testing.T) {
+		fallbacks := []string{"nonexistent"}
+		_, err := svc.UpdateTeam(ctx, "squad", domain.TeamUpdate{FallbackTeams: &fallbacks})
+		assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+	})
+}
+
+func TestCodeOwnership(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := svc.CreateTeam(ctx, domain.Team{
+		Name: "backend",
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true},
+			{ID: "u4", Username: "David", IsActive: true},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	_, err = svc.CreateTeam(ctx, domain.Team{
+		Name: "dba",
+		Members: []domain.User{
+			{ID: "d1", Username: "Dana", IsActive: true},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	rules, err := svc.ReplaceOwnershipRules(ctx, []domain.OwnershipRule{
+		{Pattern: "*", Users: []string{"u2"}},
+		{Pattern: "/internal/repository/", Teams: []string{"dba"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	t.Run("владелец затронутого пути назначается ревьюером", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{
+			ChangedFiles: []string{"internal/repository/postgres.go"},
+		})
+		require.NoError(t, err)
+		assert.Len(t, pr.AssignedReviewers, 2)
+		assert.Contains(t, pr.AssignedReviewers, "d1")
+		assert.Equal(t, []string{"internal/repository/postgres.go"}, pr.ChangedFiles)
+	})
+
+	t.Run("без других владельцев замена берется из команды автора", func(t *testing.T) {
+		pr, replacement, err := svc.ReassignReviewer(ctx, "pr1", "d1", "")
+		require.NoError(t, err)
+		assert.Contains(t, []string{"u2", "u3", "u4"}, replacement)
+		assert.NotContains(t, pr.AssignedReviewers, "d1")
+	})
+
+	t.Run("последнее совпавшее правило имеет приоритет", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr2", "PR 2", "u1", domain.PullRequestOptions{
+			ChangedFiles: []string{"README.md"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, pr.AssignedReviewers, "u2")
+	})
+
+	t.Run("CRUD правил", func(t *testing.T) {
+		rule, err := svc.CreateOwnershipRule(ctx, domain.OwnershipRule{Pattern: "*.sql", Users: []string{"u3"}})
+		require.NoError(t, err)
+		assert.Equal(t, 2, rule.Position)
+
+		pattern := "/schema/*.sql"
+		updated, err := svc.UpdateOwnershipRule(ctx, rule.ID, domain.OwnershipRuleUpdate{Pattern: &pattern})
+		require.NoError(t, err)
+		assert.Equal(t, pattern, updated.Pattern)
+		assert.Equal(t, []string{"u3"}, updated.Users)
+
+		empty := []string{}
+		_, err = svc.UpdateOwnershipRule(ctx, rule.ID, domain.OwnershipRuleUpdate{Users: &empty})
+		assert.ErrorIs(t, err, domain.ErrNoOwners)
+
+		require.NoError(t, svc.DeleteOwnershipRule(ctx, rule.ID))
+		assert.ErrorIs(t, svc.DeleteOwnershipRule(ctx, rule.ID), domain.ErrOwnershipRuleNotFound)
+	})
+
+	t.Run("владелец должен существовать", func(t *testing.T) {
+		_, err := svc.CreateOwnershipRule(ctx, domain.OwnershipRule{Pattern: "*.md", Users: []string{"nobody"}})
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+		_, err = svc.CreateOwnershipRule(ctx, domain.OwnershipRule{Pattern: "*.md", Teams: []string{"nobody"}})
+		assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+	})
+}
+
+func TestExpertiseTags(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	team, err := svc.CreateTeam(ctx, domain.Team{
+		Name: "product",
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true, Tags: []string{"go"}},
+			{ID: "u2", Username: "Bob", IsActive: true, Tags: []string{"go", "sql"}},
+			{ID: "u3", Username: "Charlie", IsActive: true, Tags: []string{"Frontend"}},
+			{ID: "u4", Username: "David", IsActive: true, Tags: []string{"go"}},
+		},
+	}, false)
+	require.NoError(t, err)
+	for _, member := range team.Members {
+		if member.ID == "u3" {
+			assert.Equal(t, []string{"frontend"}, member.Tags)
+		}
+	}
+
+	t.Run("предпочитаются кандидаты с нужными тегами", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{
+			RequiredTags: []string{"frontend", "sql"},
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"u2", "u3"}, pr.AssignedReviewers)
+		assert.Equal(t, []string{"frontend", "sql"}, pr.RequiredTags)
+	})
+
+	t.Run("при переназначении сохраняется покрытие тегов", func(t *testing.T) {
+		_, err := svc.SetUserTags(ctx, "u4", []string{"sql"})
+		require.NoError(t, err)
+
+		_, replacement, err := svc.ReassignReviewer(ctx, "pr1", "u2", "")
+		require.NoError(t, err)
+		assert.Equal(t, "u4", replacement)
+	})
+
+	t.Run("без подходящих по тегам работает обычный выбор", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr2", "PR 2", "u1", domain.PullRequestOptions{
+			RequiredTags: []string{"rust"},
+		})
+		require.NoError(t, err)
+		assert.Len(t, pr.AssignedReviewers, 2)
+	})
+
+	t.Run("теги несуществующего пользователя", func(t *testing.T) {
+		_, err := svc.SetUserTags(ctx, "nonexistent", []string{"go"})
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	})
+}
+
+func TestUnavailabilityWindows(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := svc.CreateTeam(ctx, domain.Team{
+		Name: "backend",
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true},
+			{ID: "u4", Username: "David", IsActive: true},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	now := time.Now()
+	vacation, err := svc.AddUnavailability(ctx, domain.UnavailabilityWindow{
+		UserID: "u2",
+		Start:  now.Add(-time.Hour),
+		End:    now.Add(24 * time.Hour),
+		Reason: "vacation",
+	})
+	require.NoError(t, err)
+
+	_, err = svc.AddUnavailability(ctx, domain.UnavailabilityWindow{
+		UserID: "u3",
+		Start:  now.Add(24 * time.Hour),
+		End:    now.Add(48 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	t.Run("недоступный пользователь не назначается", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"u3", "u4"}, pr.AssignedReviewers)
+	})
+
+	t.Run("окна видны в команде", func(t *testing.T) {
+		team, err := svc.GetTeam(ctx, "backend")
+		require.NoError(t, err)
+		for _, member := range team.Members {
+			switch member.ID {
+			case "u2":
+				require.Len(t, member.Unavailability, 1)
+				assert.Equal(t, "vacation", member.Unavailability[0].Reason)
+			case "u3":
+				assert.Len(t, member.Unavailability, 1)
+			default:
+				assert.Empty(t, member.Unavailability)
+			}
+			assert.True(t, member.IsActive)
+		}
+	})
+
+	t.Run("после удаления окна пользователь снова доступен", func(t *testing.T) {
+		require.NoError(t, svc.DeleteUnavailability(ctx, "u2", vacation.ID))
+
+		pr, err := svc.CreatePullRequest(ctx, "pr2", "PR 2", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, pr.AssignedReviewers, "u2")
+
+		err = svc.DeleteUnavailability(ctx, "u2", vacation.ID)
+		assert.ErrorIs(t, err, domain.ErrWindowNotFound)
+	})
+
+	t.Run("некорректное окно", func(t *testing.T) {
+		_, err := svc.AddUnavailability(ctx, domain.UnavailabilityWindow{UserID: "u1", Start: now, End: now})
+		assert.ErrorIs(t, err, domain.ErrInvalidWindow)
+
+		_, err = svc.AddUnavailability(ctx, domain.UnavailabilityWindow{UserID: "nonexistent", Start: now, End: now.Add(time.Hour)})
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+		_, err = svc.ListUnavailability(ctx, "nonexistent")
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	})
+}
+
+func TestReviewCapacity(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	team, err := svc.CreateTeam(ctx, domain.Team{
+		Name:           "backend",
+		MaxOpenReviews: 1,
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true, MaxOpenReviews: 2},
+		},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, team.MaxOpenReviews)
+
+	t.Run("лимит не мешает, пока есть свободные ревьюеры", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"u2", "u3"}, pr.AssignedReviewers)
+		assert.Nil(t, pr.Shortfall)
+	})
+
+	t.Run("ревьюер на лимите пропускается", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr2", "PR 2", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u3"}, pr.AssignedReviewers)
+		require.NotNil(t, pr.Shortfall)
+		assert.Equal(t, 1, pr.Shortfall.Missing)
+		assert.Equal(t, domain.ShortfallCapacity, pr.Shortfall.Reason)
+		assert.Equal(t, []string{"u2"}, pr.Shortfall.AtCapacity)
+	})
+
+	t.Run("PR создается, даже если все на лимите", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr3", "PR 3", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, pr.AssignedReviewers)
+		require.NotNil(t, pr.Shortfall)
+		assert.Equal(t, 2, pr.Shortfall.Missing)
+		assert.Equal(t, domain.ShortfallCapacity, pr.Shortfall.Reason)
+	})
+
+	t.Run("переназначение при заполненных лимитах", func(t *testing.T) {
+		_, _, err := svc.ReassignReviewer(ctx, "pr2", "u3", "")
+		assert.ErrorIs(t, err, domain.ErrAtCapacity)
+	})
+
+	t.Run("повышение личного лимита", func(t *testing.T) {
+		user, err := svc.SetUserCapacity(ctx, "u2", 5)
+		require.NoError(t, err)
+		assert.Equal(t, 5, user.MaxOpenReviews)
+
+		pr, err := svc.CreatePullRequest(ctx, "pr4", "PR 4", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+	})
+
+	t.Run("нехватка из-за пустой команды", func(t *testing.T) {
+		_, err := svc.CreateTeam(ctx, domain.Team{
+			Name:    "solo",
+			Members: []domain.User{{ID: "s1", Username: "Sam", IsActive: true}},
+		}, false)
+		require.NoError(t, err)
+
+		pr, err := svc.CreatePullRequest(ctx, "pr5", "PR 5", "s1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		require.NotNil(t, pr.Shortfall)
+		assert.Equal(t, domain.ShortfallNoCandidates, pr.Shortfall.Reason)
+	})
+}
+
+func TestReviewVerdicts(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := svc.CreateTeam(ctx, domain.Team{
+		Name: "backend",
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+	require.Len(t, pr.Reviews, 2)
+	for _, review := range pr.Reviews {
+		assert.Equal(t, domain.ReviewStatePending, review.State)
+		assert.False(t, review.AssignedAt.IsZero())
+		assert.Nil(t, review.SubmittedAt)
+	}
+
+	t.Run("ревьюер ставит вердикт", func(t *testing.T) {
+		updated, err := svc.SubmitReview(ctx, "pr1", "u2", domain.ReviewStateChangesRequested)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReviewStateChangesRequested, updated.Reviews["u2"].State)
+		assert.NotNil(t, updated.Reviews["u2"].SubmittedAt)
+		assert.Equal(t, domain.ReviewStatePending, updated.Reviews["u3"].State)
+
+		updated, err = svc.SubmitReview(ctx, "pr1", "u2", domain.ReviewStateApproved)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReviewStateApproved, updated.Reviews["u2"].State)
+	})
+
+	t.Run("вердикт от неназначенного пользователя", func(t *testing.T) {
+		_, err := svc.SubmitReview(ctx, "pr1", "u1", domain.ReviewStateApproved)
+		assert.ErrorIs(t, err, domain.ErrNotAssigned)
+	})
+
+	t.Run("PENDING нельзя отправить как вердикт", func(t *testing.T) {
+		_, err := svc.SubmitReview(ctx, "pr1", "u3", domain.ReviewStatePending)
+		assert.ErrorIs(t, err, domain.ErrInvalidReviewState)
+	})
+
+	t.Run("вердикт на смерженный PR", func(t *testing.T) {
+		_, err := svc.MergePullRequest(ctx, "pr1", domain.MergeOptions{})
+		require.NoError(t, err)
+
+		_, err = svc.SubmitReview(ctx, "pr1", "u3", domain.ReviewStateCommented)
+		assert.ErrorIs(t, err, domain.ErrPRMerged)
+	})
+}
+
+func TestMergeGating(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := svc.CreateTeam(ctx, domain.Team{
+		Name:              "backend",
+		RequiredApprovals: 1,
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	_, err = svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+	_, err = svc.CreatePullRequest(ctx, "pr2", "PR 2", "u1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+
+	t.Run("merge без одобрений блокируется", func(t *testing.T) {
+		_, err := svc.MergePullRequest(ctx, "pr1", domain.MergeOptions{})
+		assert.ErrorIs(t, err, domain.ErrMergeBlocked)
+	})
+
+	t.Run("запрошенные изменения блокируют merge", func(t *testing.T) {
+		_, err := svc.SubmitReview(ctx, "pr1", "u2", domain.ReviewStateApproved)
+		require.NoError(t, err)
+		_, err = svc.SubmitReview(ctx, "pr1", "u3", domain.ReviewStateChangesRequested)
+		require.NoError(t, err)
+
+		_, err = svc.MergePullRequest(ctx, "pr1", domain.MergeOptions{})
+		assert.ErrorIs(t, err, domain.ErrMergeBlocked)
+	})
+
+	t.Run("merge после одобрения", func(t *testing.T) {
+		_, err := svc.SubmitReview(ctx, "pr1", "u3", domain.ReviewStateApproved)
+		require.NoError(t, err)
+
+		merged, err := svc.MergePullRequest(ctx, "pr1", domain.MergeOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, domain.PullRequestStatusMerged, merged.Status)
+	})
+
+	t.Run("принудительный merge записывается", func(t *testing.T) {
+		merged, err := svc.MergePullRequest(ctx, "pr2", domain.MergeOptions{Force: true, Reason: "hotfix"})
+		require.NoError(t, err)
+		assert.Equal(t, domain.PullRequestStatusMerged, merged.Status)
+
+		forced, err := svc.ListForcedMerges(ctx)
+		require.NoError(t, err)
+		require.Len(t, forced, 1)
+		assert.Equal(t, "pr2", forced[0].PullRequestID)
+		assert.Equal(t, 0, forced[0].Approvals)
+		assert.Equal(t, 1, forced[0].RequiredApprovals)
+		assert.Equal(t, "hotfix", forced[0].Reason)
+		assert.NotEmpty(t, forced[0].Bypassed)
+	})
+}
+
+func TestCloseReopen(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := svc.CreateTeam(ctx, domain.Team{
+		Name: "backend",
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true},
+			{ID: "u4", Username: "Dave", IsActive: true},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	t.Run("закрытие PR", func(t *testing.T) {
+		closed, err := svc.ClosePullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PullRequestStatusClosed, closed.Status)
+		require.NotNil(t, closed.ClosedAt)
+
+		again, err := svc.ClosePullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.Equal(t, closed.ClosedAt.Unix(), again.ClosedAt.Unix())
+
+		stats, err := svc.GetPRStats(ctx, "", false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.ClosedPRs)
+		assert.Equal(t, 0, stats.OpenPRs)
+	})
+
+	t.Run("закрытый PR нельзя смержить или ревьюить", func(t *testing.T) {
+		_, err := svc.MergePullRequest(ctx, "pr1", domain.MergeOptions{})
+		assert.ErrorIs(t, err, domain.ErrPRClosed)
+
+		_, err = svc.SubmitReview(ctx, "pr1", pr.AssignedReviewers[0], domain.ReviewStateApproved)
+		assert.ErrorIs(t, err, domain.ErrPRClosed)
+
+		_, _, err = svc.ReassignReviewer(ctx, "pr1", pr.AssignedReviewers[0], "")
+		assert.ErrorIs(t, err, domain.ErrPRClosed)
+	})
+
+	t.Run("переоткрытие заменяет неактивного ревьюера", func(t *testing.T) {
+		leaving, staying := pr.AssignedReviewers[0], pr.AssignedReviewers[1]
+		_, err := svc.SetUserActivity(ctx, leaving, false)
+		require.NoError(t, err)
+
+		reopened, moves, err := svc.ReopenPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PullRequestStatusOpen, reopened.Status)
+		assert.Nil(t, reopened.ClosedAt)
+
+		require.Len(t, moves, 1)
+		assert.Equal(t, leaving, moves[0].OldReviewerID)
+		assert.NotEmpty(t, moves[0].NewReviewerID)
+		assert.ElementsMatch(t, []string{staying, moves[0].NewReviewerID}, reopened.AssignedReviewers)
+
+		again, moves, err := svc.ReopenPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.Empty(t, moves)
+		assert.ElementsMatch(t, reopened.AssignedReviewers, again.AssignedReviewers)
+	})
+
+	t.Run("переоткрытие заменяет недоступного ревьюера", func(t *testing.T) {
+		leaving, returning := pr.AssignedReviewers[1], pr.AssignedReviewers[0]
+		_, err := svc.SetUserActivity(ctx, returning, true)
+		require.NoError(t, err)
+		_, err = svc.ClosePullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		now := time.Now()
+		_, err = svc.AddUnavailability(ctx, domain.UnavailabilityWindow{
+			UserID: leaving,
+			Start:  now.Add(-time.Hour),
+			End:    now.Add(24 * time.Hour),
+		})
+		require.NoError(t, err)
+
+		reopened, moves, err := svc.ReopenPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		require.Len(t, moves, 1)
+		assert.Equal(t, leaving, moves[0].OldReviewerID)
+		assert.Equal(t, returning, moves[0].NewReviewerID)
+		assert.NotContains(t, reopened.AssignedReviewers, leaving)
+	})
+
+	t.Run("смерженный PR нельзя закрыть", func(t *testing.T) {
+		_, err := svc.MergePullRequest(ctx, "pr1", domain.MergeOptions{})
+		require.NoError(t, err)
+
+		_, err = svc.ClosePullRequest(ctx, "pr1")
+		assert.ErrorIs(t, err, domain.ErrPRMerged)
+		_, _, err = svc.ReopenPullRequest(ctx, "pr1")
+		assert.ErrorIs(t, err, domain.ErrPRMerged)
+	})
+}
+
+func TestDraftPullRequests(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := svc.CreateTeam(ctx, domain.Team{
+		Name: "backend",
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	t.Run("черновик создается без ревьюеров", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{Draft: true})
+		require.NoError(t, err)
+		assert.Equal(t, domain.PullRequestStatusDraft, pr.Status)
+		assert.Empty(t, pr.AssignedReviewers)
+		assert.Nil(t, pr.Shortfall)
+
+		prs, err := svc.ListReviewerPullRequests(ctx, "u2")
+		require.NoError(t, err)
+		assert.Empty(t, prs)
+
+		stats, err := svc.GetPRStats(ctx, "", false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.DraftPRs)
+		assert.Equal(t, 0, stats.OpenPRs)
+	})
+
+	t.Run("черновик нельзя смержить, закрыть или переоткрыть", func(t *testing.T) {
+		_, err := svc.MergePullRequest(ctx, "pr1", domain.MergeOptions{})
+		assert.ErrorIs(t, err, domain.ErrPRDraft)
+		_, err = svc.ClosePullRequest(ctx, "pr1")
+		assert.ErrorIs(t, err, domain.ErrPRDraft)
+		_, _, err = svc.ReopenPullRequest(ctx, "pr1")
+		assert.ErrorIs(t, err, domain.ErrPRDraft)
+
+		pr, err := svc.GetPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PullRequestStatusDraft, pr.Status)
+	})
+
+	t.Run("черновик не попадает в backfill", func(t *testing.T) {
+		backfilled, err := svc.BackfillReviewers(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, backfilled)
+	})
+
+	t.Run("ready назначает ревьюеров", func(t *testing.T) {
+		pr, err := svc.MarkPullRequestReady(ctx, "pr1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PullRequestStatusOpen, pr.Status)
+		assert.ElementsMatch(t, []string{"u2", "u3"}, pr.AssignedReviewers)
+
+		again, err := svc.MarkPullRequestReady(ctx, "pr1")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, pr.AssignedReviewers, again.AssignedReviewers)
+	})
+}
+
+func TestUpdatePullRequest(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := svc.CreateTeam(ctx, domain.Team{
+		Name: "backend",
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true},
+			{ID: "u4", Username: "Dave", IsActive: true},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	t.Run("переименование PR", func(t *testing.T) {
+		name := "Renamed PR"
+		updated, moves, err := svc.UpdatePullRequest(ctx, "pr1", domain.PullRequestUpdate{Name: &name})
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed PR", updated.Name)
+		assert.Equal(t, "u1", updated.AuthorID)
+		assert.Empty(t, moves)
+	})
+
+	t.Run("новый автор заменяется в ревьюерах", func(t *testing.T) {
+		newAuthor := pr.AssignedReviewers[0]
+		updated, moves, err := svc.UpdatePullRequest(ctx, "pr1", domain.PullRequestUpdate{AuthorID: &newAuthor})
+		require.NoError(t, err)
+		assert.Equal(t, newAuthor, updated.AuthorID)
+		assert.NotContains(t, updated.AssignedReviewers, newAuthor)
+		assert.Len(t, updated.AssignedReviewers, 2)
+
+		require.Len(t, moves, 1)
+		assert.Equal(t, newAuthor, moves[0].OldReviewerID)
+		assert.NotEmpty(t, moves[0].NewReviewerID)
+	})
+
+	t.Run("несуществующий автор", func(t *testing.T) {
+		missing := "nobody"
+		_, _, err := svc.UpdatePullRequest(ctx, "pr1", domain.PullRequestUpdate{AuthorID: &missing})
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	})
+
+	t.Run("автор из другой команды", func(t *testing.T) {
+		_, err := svc.CreateTeam(ctx, domain.Team{
+			Name:    "frontend",
+			Members: []domain.User{{ID: "f1", Username: "Frank", IsActive: true}},
+		}, false)
+		require.NoError(t, err)
+
+		outsider := "f1"
+		_, _, err = svc.UpdatePullRequest(ctx, "pr1", domain.PullRequestUpdate{AuthorID: &outsider})
+		assert.ErrorIs(t, err, domain.ErrNotTeamMember)
+	})
+
+	t.Run("автор не остается ревьюером без замены", func(t *testing.T) {
+		current, err := svc.GetPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		reviewer := current.AssignedReviewers[0]
+
+		_, err = repository.New(testDBPool).UpdatePullRequest(ctx, "pr1", domain.PullRequestUpdate{AuthorID: &reviewer}, nil)
+		assert.ErrorIs(t, err, domain.ErrReviewerIsAuthor)
+
+		unchanged, err := svc.GetPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.Equal(t, current.AuthorID, unchanged.AuthorID)
+	})
+
+	t.Run("смерженный PR не редактируется", func(t *testing.T) {
+		_, err := svc.MergePullRequest(ctx, "pr1", domain.MergeOptions{})
+		require.NoError(t, err)
+
+		name := "Too late"
+		_, _, err = svc.UpdatePullRequest(ctx, "pr1", domain.PullRequestUpdate{Name: &name})
+		assert.ErrorIs(t, err, domain.ErrPRMerged)
+	})
+}
+
+func TestManualReviewers(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := svc.CreateTeam(ctx, domain.Team{
+		Name:              "backend",
+		RequiredReviewers: 1,
+		Members: []domain.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Charlie", IsActive: true},
+			{ID: "u4", Username: "Dave", IsActive: true},
+			{ID: "u5", Username: "Eve", IsActive: false},
+		},
+	}, false)
+	require.NoError(t, err)
+
+	pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 1)
+	assigned := pr.AssignedReviewers[0]
+
+	var free []string
+	for _, id := range []string{"u2", "u3", "u4"} {
+		if id != assigned {
+			free = append(free, id)
+		}
+	}
+
+	t.Run("нарушения правил назначения", func(t *testing.T) {
+		_, err := svc.AddReviewer(ctx, "pr1", "u1")
+		assert.ErrorIs(t, err, domain.ErrReviewerIsAuthor)
+		_, err = svc.AddReviewer(ctx, "pr1", assigned)
+		assert.ErrorIs(t, err, domain.ErrAlreadyAssigned)
+		_, err = svc.AddReviewer(ctx, "pr1", "u5")
+		assert.ErrorIs(t, err, domain.ErrUserInactive)
+		_, err = svc.AddReviewer(ctx, "pr1", "nobody")
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+		_, err = svc.SwapReviewer(ctx, "pr1", free[0], free[1])
+		assert.ErrorIs(t, err, domain.ErrNotAssigned)
+	})
+
+	t.Run("добавление конкретного ревьюера", func(t *testing.T) {
+		updated, err := svc.AddReviewer(ctx, "pr1", free[0])
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{assigned, free[0]}, updated.AssignedReviewers)
+	})
+
+	t.Run("снятие ревьюера без замены", func(t *testing.T) {
+		updated, err := svc.RemoveReviewer(ctx, "pr1", assigned)
+		require.NoError(t, err)
+		assert.Equal(t, []string{free[0]}, updated.AssignedReviewers)
+
+		_, err = svc.RemoveReviewer(ctx, "pr1", assigned)
+		assert.ErrorIs(t, err, domain.ErrNotAssigned)
+	})
+
+	t.Run("замена на указанного ревьюера", func(t *testing.T) {
+		updated, err := svc.SwapReviewer(ctx, "pr1", free[0], free[1])
+		require.NoError(t, err)
+		assert.Equal(t, []string{free[1]}, updated.AssignedReviewers)
+		assert.Equal(t, domain.ReviewStatePending, updated.Reviews[free[1]].State)
+	})
+
+	t.Run("репозиторий повторяет проверки под блокировкой PR", func(t *testing.T) {
+		repo := repository.New(testDBPool)
+
+		_, err := repo.AddReviewers(ctx, "pr1", []string{"u5"}, nil, 0)
+		assert.ErrorIs(t, err, domain.ErrUserInactive)
+		_, err = repo.AddReviewers(ctx, "pr1", []string{"u1"}, nil, 0)
+		assert.ErrorIs(t, err, domain.ErrReviewerIsAuthor)
+		_, err = repo.AddReviewers(ctx, "pr1", []string{assigned, free[0]}, nil, 2)
+		assert.ErrorIs(t, err, domain.ErrEnoughReviewers)
+		_, err = repo.AddReviewers(ctx, "pr1", []string{assigned, assigned}, nil, 0)
+		assert.ErrorIs(t, err, domain.ErrAlreadyAssigned)
+
+		pr, err := svc.GetPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{free[1]}, pr.AssignedReviewers)
+	})
+
+	t.Run("смерженный PR", func(t *testing.T) {
+		_, err := svc.MergePullRequest(ctx, "pr1", domain.MergeOptions{})
+		require.NoError(t, err)
+
+		_, err = svc.AddReviewer(ctx, "pr1", assigned)
+		assert.ErrorIs(t, err, domain.ErrPRMerged)
+		_, err = svc.RemoveReviewer(ctx, "pr1", free[1])
+		assert.ErrorIs(t, err, domain.ErrPRMerged)
+	})
+}
+
+func TestReassignToRequestedReviewer(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, team := range []domain.Team{
+		{Name: "platform", Members: []domain.User{{ID: "p1", Username: "Pat", IsActive: true}}},
+		{Name: "frontend", Members: []domain.User{{ID: "f1", Username: "Fay", IsActive: true}}},
+		{
+			Name:              "backend",
+			RequiredReviewers: 1,
+			FallbackTeams:     []string{"platform"},
+			Members: []domain.User{
+				{ID: "u1", Username: "Alice", IsActive: true},
+				{ID: "u2", Username: "Bob", IsActive: true},
+				{ID: "u3", Username: "Charlie", IsActive: false},
+			},
+		},
+	} {
+		_, err := svc.CreateTeam(ctx, team, false)
+		require.NoError(t, err)
+	}
+
+	pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+
+	t.Run("недопустимые кандидаты", func(t *testing.T) {
+		tests := []struct {
+			newUserID string
+			want      error
+		}{
+			{newUserID: "u1", want: domain.ErrReviewerIsAuthor},
+			{newUserID: "u2", want: domain.ErrAlreadyAssigned},
+			{newUserID: "u3", want: domain.ErrUserInactive},
+			{newUserID: "f1", want: domain.ErrTeamNotAllowed},
+			{newUserID: "nobody", want: domain.ErrUserNotFound},
+		}
+		for _, tt := range tests {
+			_, _, err := svc.ReassignReviewer(ctx, "pr1", "u2", tt.newUserID)
+			assert.ErrorIs(t, err, tt.want, tt.newUserID)
+		}
+	})
+
+	t.Run("назначение указанного ревьюера из резервной команды", func(t *testing.T) {
+		updated, replacement, err := svc.ReassignReviewer(ctx, "pr1", "u2", "p1")
+		require.NoError(t, err)
+		assert.Equal(t, "p1", replacement)
+		assert.Equal(t, []string{"p1"}, updated.AssignedReviewers)
+		assert.Equal(t, "platform", updated.FallbackReviewers["p1"])
+	})
+}
+
+func TestDeclineReview(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, team := range []domain.Team{
+		{
+			Name:              "backend",
+			RequiredReviewers: 1,
+			Members: []domain.User{
+				{ID: "u1", Username: "Alice", IsActive: true},
+				{ID: "u2", Username: "Bob", IsActive: true},
+				{ID: "u3", Username: "Charlie", IsActive: true},
+			},
+		},
+		{
+			Name:              "solo",
+			RequiredReviewers: 1,
+			Members: []domain.User{
+				{ID: "s1", Username: "Sam", IsActive: true},
+				{ID: "s2", Username: "Taylor", IsActive: true},
+			},
+		},
+	} {
+		_, err := svc.CreateTeam(ctx, team, false)
+		require.NoError(t, err)
+	}
+
+	pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 1)
+	decliner := pr.AssignedReviewers[0]
+
+	t.Run("отказ с заменой", func(t *testing.T) {
+		updated, replacement, err := svc.DeclineReview(ctx, "pr1", decliner, "не знаю этот код")
+		require.NoError(t, err)
+		assert.NotEqual(t, decliner, replacement)
+		assert.Equal(t, []string{replacement}, updated.AssignedReviewers)
+		assert.Nil(t, updated.Shortfall)
+
+		_, _, err = svc.DeclineReview(ctx, "pr1", decliner, "еще раз")
+		assert.ErrorIs(t, err, domain.ErrNotAssigned)
+	})
+
+	t.Run("отказ без кандидатов", func(t *testing.T) {
+		_, err := svc.CreatePullRequest(ctx, "pr2", "PR 2", "s1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+
+		updated, replacement, err := svc.DeclineReview(ctx, "pr2", "s2", "в отпуске")
+		require.NoError(t, err)
+		assert.Empty(t, replacement)
+		assert.Empty(t, updated.AssignedReviewers)
+		require.NotNil(t, updated.Shortfall)
+		assert.Equal(t, 1, updated.Shortfall.Missing)
+	})
+
+	t.Run("отказы в статистике", func(t *testing.T) {
+		stats, err := svc.GetReviewerStats(ctx, "", false)
+		require.NoError(t, err)
+
+		declines := make(map[string]int)
+		for _, s := range stats {
+			declines[s.UserID] = s.Declines
+		}
+		assert.Equal(t, 1, declines[decliner])
+		assert.Equal(t, 1, declines["s2"])
+		assert.Equal(t, 0, declines["u1"])
+	})
+}
+
+func TestReviewSLA(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, team := range []domain.Team{
+		{
+			Name:              "backend",
+			RequiredReviewers: 1,
+			ReviewSLAHours:    1,
+			SLAAction:         domain.SLAActionReassign,
+			Members: []domain.User{
+				{ID: "u1", Username: "Alice", IsActive: true},
+				{ID: "u2", Username: "Bob", IsActive: true},
+				{ID: "u3", Username: "Charlie", IsActive: true},
+			},
+		},
+		{
+			Name:              "ops",
+			RequiredReviewers: 1,
+			ReviewSLAHours:    1,
+			SLAAction:         domain.SLAActionAddReviewer,
+			Members: []domain.User{
+				{ID: "o1", Username: "Olga", IsActive: true},
+				{ID: "o2", Username: "Oleg", IsActive: true},
+				{ID: "o3", Username: "Oscar", IsActive: true},
+			},
+		},
+		{
+			Name:              "frontend",
+			RequiredReviewers: 1,
+			Members: []domain.User{
+				{ID: "f1", Username: "Fay", IsActive: true},
+				{ID: "f2", Username: "Finn", IsActive: true},
+			},
+		},
+	} {
+		_, err := svc.CreateTeam(ctx, team, false)
+		require.NoError(t, err)
+	}
+
+	pr1, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+	pr2, err := svc.CreatePullRequest(ctx, "pr2", "PR 2", "o1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+	_, err = svc.CreatePullRequest(ctx, "pr3", "PR 3", "f1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+
+	later := time.Now().Add(7 * 24 * time.Hour)
+
+	t.Run("просрочка считается только после SLA", func(t *testing.T) {
+		overdue, err := svc.ListOverdueReviews(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, overdue)
+
+		overdue, err = svc.ListOverdueReviews(ctx, later)
+		require.NoError(t, err)
+		require.Len(t, overdue, 2)
+		for _, item := range overdue {
+			assert.NotEqual(t, "pr3", item.PullRequestID)
+			assert.True(t, item.DueAt().After(item.AssignedAt))
+		}
+	})
+
+	t.Run("эскалация по политике команды", func(t *testing.T) {
+		escalations, err := svc.EscalateOverdueReviews(ctx, later)
+		require.NoError(t, err)
+		require.Len(t, escalations, 2)
+
+		reassigned, err := svc.GetPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		require.Len(t, reassigned.AssignedReviewers, 1)
+		assert.NotEqual(t, pr1.AssignedReviewers[0], reassigned.AssignedReviewers[0])
+
+		extended, err := svc.GetPullRequest(ctx, "pr2")
+		require.NoError(t, err)
+		assert.Len(t, extended.AssignedReviewers, 2)
+		assert.Contains(t, extended.AssignedReviewers, pr2.AssignedReviewers[0])
+	})
+
+	t.Run("повторной эскалации нет", func(t *testing.T) {
+		escalations, err := svc.EscalateOverdueReviews(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, escalations)
+
+		overdue, err := svc.ListOverdueReviews(ctx, later)
+		require.NoError(t, err)
+		for _, item := range overdue {
+			if item.ReviewerID == pr2.AssignedReviewers[0] {
+				assert.NotNil(t, item.EscalatedAt)
+			}
+		}
+	})
+
+	t.Run("вердикт снимает просрочку", func(t *testing.T) {
+		_, err := svc.SubmitReview(ctx, "pr2", pr2.AssignedReviewers[0], domain.ReviewStateApproved)
+		require.NoError(t, err)
+
+		overdue, err := svc.ListOverdueReviews(ctx, later)
+		require.NoError(t, err)
+		for _, item := range overdue {
+			assert.NotEqual(t, pr2.AssignedReviewers[0], item.ReviewerID)
+		}
+	})
+}
+
+func TestPriorityAndSize(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, team := range []domain.Team{
+		{
+			Name:              "backend",
+			RequiredReviewers: 1,
+			FallbackTeams:     []string{"platform"},
+			Members: []domain.User{
+				{ID: "u1", Username: "Alice", IsActive: true},
+				{ID: "u2", Username: "Bob", IsActive: true},
+			},
+		},
+		{
+			Name:              "platform",
+			RequiredReviewers: 1,
+			Members: []domain.User{
+				{ID: "p1", Username: "Pavel", IsActive: true},
+			},
+		},
+		{
+			Name:              "data",
+			RequiredReviewers: 1,
+			MaxOpenReviews:    2,
+			Members: []domain.User{
+				{ID: "d1", Username: "Dana", IsActive: true},
+				{ID: "d2", Username: "Dmitry", IsActive: true},
+				{ID: "d3", Username: "Daria", IsActive: true},
+			},
+		},
+	} {
+		_, err := svc.CreateTeam(ctx, team, false)
+		require.NoError(t, err)
+	}
+
+	t.Run("срочный PR уходит наименее загруженному, в том числе из резервной команды", func(t *testing.T) {
+		normal, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, domain.PriorityNormal, normal.Priority)
+		assert.Equal(t, []string{"u2"}, normal.AssignedReviewers)
+
+		urgent, err := svc.CreatePullRequest(ctx, "pr2", "PR 2", "u1", domain.PullRequestOptions{Priority: domain.PriorityUrgent})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"p1"}, urgent.AssignedReviewers)
+		assert.Equal(t, "platform", urgent.FallbackReviewers["p1"])
+
+		stored, err := svc.GetPullRequest(ctx, "pr2")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PriorityUrgent, stored.Priority)
+	})
+
+	t.Run("обычный PR не уходит в резервную команду без нужды", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr3", "PR 3", "u1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+	})
+
+	t.Run("большой PR занимает несколько единиц нагрузки", func(t *testing.T) {
+		big, err := svc.CreatePullRequest(ctx, "big", "Big", "d1", domain.PullRequestOptions{SizeLines: 3 * domain.LinesPerLoadUnit})
+		require.NoError(t, err)
+		require.Len(t, big.AssignedReviewers, 1)
+		assert.Equal(t, 3, big.LoadUnits())
+		busy := big.AssignedReviewers[0]
+
+		small, err := svc.CreatePullRequest(ctx, "small1", "Small 1", "d1", domain.PullRequestOptions{SizeLines: 40})
+		require.NoError(t, err)
+		require.Len(t, small.AssignedReviewers, 1)
+		other := small.AssignedReviewers[0]
+		assert.NotEqual(t, busy, other)
+
+		small, err = svc.CreatePullRequest(ctx, "small2", "Small 2", "d1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{other}, small.AssignedReviewers)
+
+		full, err := svc.CreatePullRequest(ctx, "small3", "Small 3", "d1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, full.AssignedReviewers)
+		require.NotNil(t, full.Shortfall)
+		assert.Equal(t, domain.ShortfallCapacity, full.Shortfall.Reason)
+		assert.ElementsMatch(t, []string{busy, other}, full.Shortfall.AtCapacity)
+	})
+
+	t.Run("отрицательный размер отклоняется", func(t *testing.T) {
+		_, err := svc.CreatePullRequest(ctx, "bad", "Bad", "d1", domain.PullRequestOptions{SizeLines: -1})
+		assert.Error(t, err)
+	})
+}
+
+func TestTeamMembership(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, team := range []domain.Team{
+		{
+			Name:              "backend",
+			RequiredReviewers: 1,
+			Members: []domain.User{
+				{ID: "u1", Username: "Alice", IsActive: true},
+				{ID: "u2", Username: "Bob", IsActive: true},
+			},
+		},
+		{
+			Name:              "platform",
+			RequiredReviewers: 1,
+			Members: []domain.User{
+				{ID: "p1", Username: "Pavel", IsActive: true},
+			},
+		},
+	} {
+		_, err := svc.CreateTeam(ctx, team, false)
+		require.NoError(t, err)
+	}
+	_, err := svc.UpdateTeam(ctx, "backend", domain.TeamUpdate{FallbackTeams: &[]string{"platform"}})
+	require.NoError(t, err)
+
+	t.Run("добавление участников в существующую команду", func(t *testing.T) {
+		team, err := svc.AddTeamMembers(ctx, "backend", []domain.User{
+			{ID: "u3", Username: "Charlie", IsActive: true, Tags: []string{"SQL"}},
+		}, false)
+		require.NoError(t, err)
+		require.Len(t, team.Members, 3)
+		for _, member := range team.Members {
+			if member.ID == "u3" {
+				assert.Equal(t, []string{"sql"}, member.Tags)
+			}
+		}
+
+		_, err = svc.AddTeamMembers(ctx, "missing", []domain.User{{ID: "x1", Username: "X", IsActive: true}}, false)
+		assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+
+		_, err = svc.AddTeamMembers(ctx, "backend", []domain.User{
+			{ID: "x1", Username: "X", IsActive: true},
+			{ID: "x1", Username: "X", IsActive: true},
+		}, false)
+		assert.ErrorIs(t, err, domain.ErrInvalidMembers)
+	})
+
+	t.Run("повторное добавление без тегов и лимита их не сбрасывает", func(t *testing.T) {
+		_, err := svc.SetUserCapacity(ctx, "u3", 2)
+		require.NoError(t, err)
+
+		team, err := svc.AddTeamMembers(ctx, "backend", []domain.User{
+			{ID: "u3", Username: "Charlie", IsActive: true},
+		}, false)
+		require.NoError(t, err)
+		for _, member := range team.Members {
+			if member.ID == "u3" {
+				assert.Equal(t, []string{"sql"}, member.Tags)
+				assert.Equal(t, 2, member.MaxOpenReviews)
+			}
+		}
+	})
+
+	pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 1)
+	reviewer := pr.AssignedReviewers[0]
+
+	t.Run("участник с открытыми ревью не удаляется без переназначения", func(t *testing.T) {
+		_, _, err := svc.RemoveTeamMembers(ctx, "backend", []string{reviewer}, false)
+		assert.ErrorIs(t, err, domain.ErrMemberHasOpenReviews)
+
+		team, err := svc.GetTeam(ctx, "backend")
+		require.NoError(t, err)
+		assert.Len(t, team.Members, 3)
+	})
+
+	t.Run("автор открытого PR не удаляется", func(t *testing.T) {
+		_, _, err := svc.RemoveTeamMembers(ctx, "backend", []string{"u1"}, true)
+		assert.ErrorIs(t, err, domain.ErrMemberHasOpenPRs)
+	})
+
+	t.Run("удаление с переназначением", func(t *testing.T) {
+		team, moves, err := svc.RemoveTeamMembers(ctx, "backend", []string{reviewer}, true)
+		require.NoError(t, err)
+		assert.Len(t, team.Members, 2)
+		require.Len(t, moves, 1)
+		assert.Equal(t, reviewer, moves[0].OldReviewerID)
+		assert.NotEmpty(t, moves[0].NewReviewerID)
+
+		updated, err := svc.GetPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.NotContains(t, updated.AssignedReviewers, reviewer)
+		for _, member := range team.Members {
+			assert.NotEqual(t, reviewer, member.ID)
+		}
+	})
+
+	t.Run("удалить можно только участника команды", func(t *testing.T) {
+		_, _, err := svc.RemoveTeamMembers(ctx, "backend", []string{"p1"}, true)
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	})
+
+	t.Run("переименование команды", func(t *testing.T) {
+		team, err := svc.RenameTeam(ctx, "platform", "infra")
+		require.NoError(t, err)
+		assert.Equal(t, "infra", team.Name)
+		assert.Len(t, team.Members, 1)
+
+		backend, err := svc.GetTeam(ctx, "backend")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"infra"}, backend.FallbackTeams)
+
+		_, err = svc.GetTeam(ctx, "platform")
+		assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+
+		_, err = svc.RenameTeam(ctx, "infra", "backend")
+		assert.ErrorIs(t, err, domain.ErrTeamExists)
+	})
+}
+
+func TestMoveUser(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, team := range []domain.Team{
+		{
+			Name:              "backend",
+			RequiredReviewers: 1,
+			Members: []domain.User{
+				{ID: "u1", Username: "Alice", IsActive: true},
+				{ID: "u2", Username: "Bob", IsActive: true},
+				{ID: "u3", Username: "Charlie", IsActive: true},
+			},
+		},
+		{
+			Name:              "frontend",
+			RequiredReviewers: 1,
+			Members: []domain.User{
+				{ID: "f1", Username: "Fay", IsActive: true},
+				{ID: "f2", Username: "Finn", IsActive: true},
+			},
+		},
+	} {
+		_, err := svc.CreateTeam(ctx, team, false)
+		require.NoError(t, err)
+	}
+
+	t.Run("добавление участника другой команды требует разрешения", func(t *testing.T) {
+		_, err := svc.AddTeamMembers(ctx, "frontend", []domain.User{{ID: "u3", Username: "Charlie", IsActive: true}}, false)
+		assert.ErrorIs(t, err, domain.ErrUserInOtherTeam)
+
+		team, err := svc.GetTeam(ctx, "backend")
+		require.NoError(t, err)
+		assert.Len(t, team.Members, 3)
+	})
+
+	pr, err := svc.CreatePullRequest(ctx, "pr1", "PR 1", "u1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 1)
+	reviewer := pr.AssignedReviewers[0]
+
+	t.Run("перенос без переназначения оставляет ревью", func(t *testing.T) {
+		other := "u2"
+		if reviewer == "u2" {
+			other = "u3"
+		}
+		user, moves, err := svc.MoveUser(ctx, other, "frontend", false)
+		require.NoError(t, err)
+		assert.Equal(t, "frontend", user.TeamName)
+		assert.Empty(t, moves)
+
+		history, err := svc.ListTeamMoves(ctx, other)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, "backend", history[0].FromTeam)
+		assert.Equal(t, "frontend", history[0].ToTeam)
+		assert.Equal(t, 0, history[0].Reassigned)
+
+		// Вернуть обратно, чтобы в backend остался кандидат на замену
+		_, _, err = svc.MoveUser(ctx, other, "backend", false)
+		require.NoError(t, err)
+	})
+
+	t.Run("перенос с переназначением ревью старой команды", func(t *testing.T) {
+		user, moves, err := svc.MoveUser(ctx, reviewer, "frontend", true)
+		require.NoError(t, err)
+		assert.Equal(t, "frontend", user.TeamName)
+		require.Len(t, moves, 1)
+		assert.Equal(t, reviewer, moves[0].OldReviewerID)
+		assert.NotEmpty(t, moves[0].NewReviewerID)
+
+		updated, err := svc.GetPullRequest(ctx, "pr1")
+		require.NoError(t, err)
+		assert.NotContains(t, updated.AssignedReviewers, reviewer)
+
+		history, err := svc.ListTeamMoves(ctx, reviewer)
+		require.NoError(t, err)
+		require.NotEmpty(t, history)
+		assert.Equal(t, 1, history[len(history)-1].Reassigned)
+	})
+
+	t.Run("перенос в ту же команду ничего не меняет", func(t *testing.T) {
+		_, moves, err := svc.MoveUser(ctx, reviewer, "frontend", true)
+		require.NoError(t, err)
+		assert.Empty(t, moves)
+
+		history, err := svc.ListTeamMoves(ctx, reviewer)
+		require.NoError(t, err)
+		assert.Len(t, history, 1)
+	})
+
+	t.Run("перенос в несуществующую команду", func(t *testing.T) {
+		_, _, err := svc.MoveUser(ctx, "u1", "missing", false)
+		assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+	})
+}
+
+func TestTeamArchival(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, team := range []domain.Team{
+		{
+			Name:              "legacy",
+			RequiredReviewers: 1,
+			Members: []domain.User{
+				{ID: "l1", Username: "Lena", IsActive: true},
+				{ID: "l2", Username: "Leo", IsActive: true},
+			},
+		},
+		{
+			Name:              "core",
+			RequiredReviewers: 2,
+			FallbackTeams:     []string{"legacy"},
+			Members: []domain.User{
+				{ID: "c1", Username: "Carl", IsActive: true},
+				{ID: "c2", Username: "Cora", IsActive: true},
+			},
+		},
+		{
+			Name:              "sandbox",
+			RequiredReviewers: 1,
+			Members: []domain.User{
+				{ID: "s1", Username: "Sam", IsActive: true},
+			},
+		},
+	} {
+		_, err := svc.CreateTeam(ctx, team, false)
+		require.NoError(t, err)
+	}
+
+	_, err := svc.CreatePullRequest(ctx, "pr-legacy", "Legacy PR", "l1", domain.PullRequestOptions{})
+	require.NoError(t, err)
+
+	t.Run("архивная команда не принимает новые PR", func(t *testing.T) {
+		team, moves, err := svc.ArchiveTeam(ctx, "legacy", false)
+		require.NoError(t, err)
+		assert.True(t, team.Archived())
+		assert.Empty(t, moves)
+
+		_, err = svc.CreatePullRequest(ctx, "pr-legacy-2", "Legacy PR 2", "l2", domain.PullRequestOptions{})
+		assert.ErrorIs(t, err, domain.ErrTeamArchived)
+	})
+
+	t.Run("участники архивной команды не попадают в кандидаты", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr-core", "Core PR", "c1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c2"}, pr.AssignedReviewers)
+		require.NotNil(t, pr.Shortfall)
+		assert.Equal(t, 1, pr.Shortfall.Missing)
+	})
+
+	t.Run("статистика архивной команды доступна", func(t *testing.T) {
+		stats, err := svc.GetPRStats(ctx, "legacy", false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.TotalPRs)
+
+		reviewers, err := svc.GetReviewerStats(ctx, "legacy", false)
+		require.NoError(t, err)
+		assert.Len(t, reviewers, 2)
+
+		_, err = svc.GetPRStats(ctx, "unknown", false)
+		assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+	})
+
+	t.Run("команду с PR нельзя удалить", func(t *testing.T) {
+		err := svc.DeleteTeam(ctx, "legacy")
+		assert.ErrorIs(t, err, domain.ErrTeamHasPRs)
+	})
+
+	t.Run("разархивирование возвращает команду", func(t *testing.T) {
+		team, err := svc.UnarchiveTeam(ctx, "legacy")
+		require.NoError(t, err)
+		assert.False(t, team.Archived())
+
+		_, err = svc.CreatePullRequest(ctx, "pr-legacy-2", "Legacy PR 2", "l2", domain.PullRequestOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("удаление команды без PR", func(t *testing.T) {
+		_, err := svc.UpdateTeam(ctx, "core", domain.TeamUpdate{FallbackTeams: &[]string{"sandbox"}})
+		require.NoError(t, err)
+		pr, err := svc.AddReviewer(ctx, "pr-core", "s1")
+		require.NoError(t, err)
+		assert.Equal(t, "sandbox", pr.FallbackReviewers["s1"])
+
+		require.NoError(t, svc.DeleteTeam(ctx, "sandbox"))
+
+		_, err = svc.GetTeam(ctx, "sandbox")
+		assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+
+		pr, err = svc.GetPullRequest(ctx, "pr-core")
+		require.NoError(t, err)
+		assert.Contains(t, pr.AssignedReviewers, "s1")
+		assert.NotContains(t, pr.FallbackReviewers, "s1")
+
+		core, err := svc.GetTeam(ctx, "core")
+		require.NoError(t, err)
+		assert.Empty(t, core.FallbackTeams)
+
+		history, err := svc.ListTeamMoves(ctx, "s1")
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, "sandbox", history[0].FromTeam)
+		assert.Empty(t, history[0].ToTeam)
+	})
+}
+
+func TestMultipleTeams(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, team := range []domain.Team{
+		{
+			Name:              "backend",
+			RequiredReviewers: 1,
+			Members: []domain.User{
+				{ID: "u1", Username: "Alice", IsActive: true},
+				{ID: "u2", Username: "Bob", IsActive: true},
+			},
+		},
+		{
+			Name:              "payments",
+			RequiredReviewers: 1,
+			Members: []domain.User{
+				{ID: "p1", Username: "Pam", IsActive: true},
+			},
+		},
+	} {
+		_, err := svc.CreateTeam(ctx, team, false)
+		require.NoError(t, err)
+	}
+
+	t.Run("пользователь вступает во вторую команду", func(t *testing.T) {
+		user, err := svc.JoinTeam(ctx, "u2", "payments")
+		require.NoError(t, err)
+		assert.Equal(t, "backend", user.TeamName)
+		assert.Equal(t, []string{"backend", "payments"}, user.Teams)
+
+		payments, err := svc.GetTeam(ctx, "payments")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"p1", "u2"}, memberIDs(payments.Members))
+
+		backend, err := svc.GetTeam(ctx, "backend")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"u1", "u2"}, memberIDs(backend.Members))
+
+		history, err := svc.ListTeamMoves(ctx, "u2")
+		require.NoError(t, err)
+		assert.Empty(t, history)
+	})
+
+	t.Run("участник второй команды ревьюит ее PR", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr-pay", "Payments PR", "p1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "payments", pr.TeamName)
+		assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+
+		prs, err := svc.ListReviewerPullRequests(ctx, "u2")
+		require.NoError(t, err)
+		require.Len(t, prs, 1)
+		assert.Equal(t, "pr-pay", prs[0].ID)
+	})
+
+	t.Run("PR создается в контексте выбранной команды", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr-u2-pay", "Bob in payments", "u2", domain.PullRequestOptions{TeamName: "payments"})
+		require.NoError(t, err)
+		assert.Equal(t, "payments", pr.TeamName)
+		assert.Equal(t, []string{"p1"}, pr.AssignedReviewers)
+
+		pr, err = svc.CreatePullRequest(ctx, "pr-u2", "Bob in backend", "u2", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "backend", pr.TeamName)
+		assert.Equal(t, []string{"u1"}, pr.AssignedReviewers)
+
+		stats, err := svc.GetPRStats(ctx, "payments", false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, stats.TotalPRs)
+	})
+
+	t.Run("PR от имени чужой команды", func(t *testing.T) {
+		_, err := svc.CreatePullRequest(ctx, "pr-u1-pay", "Alice in payments", "u1", domain.PullRequestOptions{TeamName: "payments"})
+		assert.ErrorIs(t, err, domain.ErrNotTeamMember)
+	})
+
+	t.Run("выход из второй команды", func(t *testing.T) {
+		_, _, err := svc.RemoveTeamMembers(ctx, "payments", []string{"u2"}, true)
+		assert.ErrorIs(t, err, domain.ErrMemberHasOpenPRs)
+
+		_, err = svc.MergePullRequest(ctx, "pr-u2-pay", domain.MergeOptions{})
+		require.NoError(t, err)
+
+		_, _, err = svc.RemoveTeamMembers(ctx, "payments", []string{"u2"}, false)
+		assert.ErrorIs(t, err, domain.ErrMemberHasOpenReviews)
+
+		team, moves, err := svc.RemoveTeamMembers(ctx, "payments", []string{"u2"}, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"p1"}, memberIDs(team.Members))
+		require.Len(t, moves, 1)
+		assert.Equal(t, "pr-pay", moves[0].PullRequestID)
+
+		pr, err := svc.GetPullRequest(ctx, "pr-pay")
+		require.NoError(t, err)
+		assert.Empty(t, pr.AssignedReviewers)
+
+		backend, err := svc.GetTeam(ctx, "backend")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"u1", "u2"}, memberIDs(backend.Members))
+	})
+}
+
+func TestTeamHierarchy(t *testing.T) {
+	svc, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, team := range []domain.Team{
+		{
+			Name: "payments",
+			Members: []domain.User{
+				{ID: "p1", Username: "Pam", IsActive: true},
+				{ID: "p2", Username: "Pete", IsActive: true},
+			},
+		},
+		{
+			Name:       "payments-api",
+			ParentTeam: "payments",
+			Members: []domain.User{
+				{ID: "a1", Username: "Ann", IsActive: true},
+				{ID: "a2", Username: "Andy", IsActive: true},
+			},
+		},
+		{
+			Name:              "payments-api-v2",
+			ParentTeam:        "payments-api",
+			RequiredReviewers: 3,
+			Members: []domain.User{
+				{ID: "v1", Username: "Val", IsActive: true},
+			},
+		},
+	} {
+		_, err := svc.CreateTeam(ctx, team, false)
+		require.NoError(t, err)
+	}
+
+	t.Run("недостающий ревьюер берется из родительской команды", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr-api", "API PR", "a1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		require.Len(t, pr.AssignedReviewers, 2)
+		assert.Contains(t, pr.AssignedReviewers, "a2")
+		require.Len(t, pr.FallbackReviewers, 1)
+		for reviewerID, team := range pr.FallbackReviewers {
+			assert.Contains(t, []string{"p1", "p2"}, reviewerID)
+			assert.Equal(t, "payments", team)
+		}
+	})
+
+	t.Run("подъем через несколько уровней", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr-v2", "V2 PR", "v1", domain.PullRequestOptions{})
+		require.NoError(t, err)
+		require.Len(t, pr.AssignedReviewers, 3)
+		assert.Contains(t, pr.AssignedReviewers, "a1")
+		assert.Contains(t, pr.AssignedReviewers, "a2")
+		assert.Equal(t, "payments-api", pr.FallbackReviewers["a1"])
+		assert.Equal(t, "payments-api", pr.FallbackReviewers["a2"])
+	})
+
+	t.Run("команда возвращается с поддеревом", func(t *testing.T) {
+		team, err := svc.GetTeamTree(ctx, "payments")
+		require.NoError(t, err)
+		assert.Empty(t, team.ParentTeam)
+		require.Len(t, team.Subteams, 1)
+		api := team.Subteams[0]
+		assert.Equal(t, "payments-api", api.Name)
+		assert.Equal(t, "payments", api.ParentTeam)
+		assert.ElementsMatch(t, []string{"a1", "a2"}, memberIDs(api.Members))
+		require.Len(t, api.Subteams, 1)
+		assert.Equal(t, "payments-api-v2", api.Subteams[0].Name)
+		assert.Empty(t, api.Subteams[0].Subteams)
+	})
+
+	t.Run("статистика по узлу дерева", func(t *testing.T) {
+		stats, err := svc.GetPRStats(ctx, "payments", false)
+		require.NoError(t, err)
+		assert.Equal(t, 0, stats.TotalPRs)
+
+		stats, err = svc.GetPRStats(ctx, "payments", true)
+		require.NoError(t, err)
+		assert.Equal(t, 2, stats.TotalPRs)
+
+		stats, err = svc.GetPRStats(ctx, "payments-api", true)
+		require.NoError(t, err)
+		assert.Equal(t, 2, stats.TotalPRs)
+
+		assignments := func(subtree bool) map[string]int {
+			reviewers, err := svc.GetReviewerStats(ctx, "payments-api", subtree)
+			require.NoError(t, err)
+			out := make(map[string]int, len(reviewers))
+			for _, s := range reviewers {
+				out[s.UserID] = s.TotalAssignments
+			}
+			return out
+		}
+		assert.Equal(t, map[string]int{"a1": 0, "a2": 1}, assignments(false))
+		assert.Equal(t, map[string]int{"a1": 1, "a2": 2, "v1": 0}, assignments(true))
+	})
+
+	t.Run("цикл в иерархии запрещен", func(t *testing.T) {
+		parent := "payments-api-v2"
+		_, err := svc.UpdateTeam(ctx, "payments", domain.TeamUpdate{ParentTeam: &parent})
+		assert.ErrorIs(t, err, domain.ErrTeamCycle)
+
+		unknown := "nonexistent"
+		_, err = svc.UpdateTeam(ctx, "payments", domain.TeamUpdate{ParentTeam: &unknown})
+		assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+	})
+
+	t.Run("команда отсоединяется от родителя", func(t *testing.T) {
+		root := ""
+		team, err := svc.UpdateTeam(ctx, "payments-api-v2", domain.TeamUpdate{ParentTeam: &root})
+		require.NoError(t, err)
+		assert.Empty(t, team.ParentTeam)
+
+		tree, err := svc.GetTeamTree(ctx, "payments-api")
+		require.NoError(t, err)
+		assert.Empty(t, tree.Subteams)
+	})
+}
+
+func memberIDs(users []domain.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}